@@ -1,5 +1,9 @@
 package ast
 
+import (
+	"github.com/midbel/enjoy/token"
+)
+
 type Node interface {
 	// Token() Token
 }
@@ -8,6 +12,11 @@ type ValueNode[T float64 | string | bool] struct {
 	Literal T
 }
 
+type RegexNode struct {
+	Pattern string
+	Flags   string
+}
+
 func CreateValue[T float64 | string | bool](v T) ValueNode[T] {
 	return ValueNode[T]{
 		Literal: v,
@@ -37,15 +46,21 @@ func Array(list []Node) ArrayNode {
 }
 
 type ObjectNode struct {
-	List map[string]Node
+	List []Node
 }
 
-func Object(list map[string]Node) ObjectNode {
+func Object(list []Node) ObjectNode {
 	return ObjectNode{
 		List: list,
 	}
 }
 
+type PropNode struct {
+	Key      Node
+	Value    Node
+	Computed bool
+}
+
 type ExportNode struct {
 	Node
 	Default bool
@@ -100,11 +115,13 @@ func Import(n Node, file string) ImportNode {
 
 type LabelNode struct {
 	Ident string
+	Node
 }
 
-func Label(ident string) LabelNode {
+func Label(ident string, node Node) LabelNode {
 	return LabelNode{
 		Ident: ident,
+		Node:  node,
 	}
 }
 
@@ -177,8 +194,9 @@ func Continue(str string) ContinueNode {
 }
 
 type MemberNode struct {
-	Curr Node
-	Next Node
+	Curr     Node
+	Next     Node
+	Optional bool
 }
 
 type LetNode struct {
@@ -191,9 +209,15 @@ type ConstNode struct {
 	Expr  Node
 }
 
+type VarDeclNode struct {
+	Ident Node
+	Expr  Node
+}
+
 type AssignNode struct {
 	Ident Node
 	Expr  Node
+	Op    rune
 }
 
 type BindingArrayNode struct {
@@ -207,10 +231,10 @@ func BindArray(list []Node) BindingArrayNode {
 }
 
 type BindingObjectNode struct {
-	List map[string]Node
+	List []Node
 }
 
-func BindObject(list map[string]Node) BindingObjectNode {
+func BindObject(list []Node) BindingObjectNode {
 	return BindingObjectNode{
 		List: list,
 	}
@@ -221,6 +245,11 @@ type UnaryNode struct {
 	Expr Node
 }
 
+type PostfixNode struct {
+	Op   rune
+	Expr Node
+}
+
 type BinaryNode struct {
 	Op    rune
 	Left  Node
@@ -228,8 +257,9 @@ type BinaryNode struct {
 }
 
 type IndexNode struct {
-	Expr  Node
-	Index Node
+	Expr     Node
+	Index    Node
+	Optional bool
 }
 
 type TryNode struct {
@@ -254,9 +284,8 @@ type IfNode struct {
 }
 
 type SwitchNode struct {
-	Cdt     Node
-	Cases   []Node
-	Default Node
+	Cdt   Node
+	Cases []Node
 }
 
 type CaseNode struct {
@@ -297,23 +326,75 @@ type LoopNode struct {
 }
 
 type FuncNode struct {
-	Ident string
-	Args  Node
-	Body  Node
+	Ident     string
+	Args      Node
+	Body      Node
+	Generator bool
+	Async     bool
 }
 
 type ArrowNode struct {
-	Args Node
-	Body Node
+	Args  Node
+	Body  Node
+	Async bool
 }
 
 type ReturnNode struct {
 	Node
 }
 
+type YieldNode struct {
+	Node
+	Delegate bool
+}
+
+type AwaitNode struct {
+	Node
+}
+
 type CallNode struct {
+	Ident    Node
+	Args     Node
+	Optional bool
+	token.Position
+}
+
+type NewNode struct {
 	Ident Node
 	Args  Node
+	token.Position
+}
+
+type ThisNode struct{}
+
+type SuperNode struct{}
+
+type ClassNode struct {
+	Ident   string
+	Parent  Node
+	Ctor    Node
+	Members []Node
+}
+
+type MethodNode struct {
+	Ident  string
+	Key    Node
+	Static bool
+	Func   Node
+}
+
+type AccessorNode struct {
+	Ident  string
+	Key    Node
+	Static bool
+	Get    Node
+	Set    Node
+}
+
+type FieldNode struct {
+	Ident  string
+	Static bool
+	Expr   Node
 }
 
 type TypeofNode struct {
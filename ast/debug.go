@@ -23,6 +23,10 @@ func debug(n Node, level int, w io.Writer) error {
 		fmt.Fprint(w, prefix)
 		fmt.Fprintf(w, "string(%s)", n.Literal)
 		fmt.Fprintln(w)
+	case RegexNode:
+		fmt.Fprint(w, prefix)
+		fmt.Fprintf(w, "regex(/%s/%s)", n.Pattern, n.Flags)
+		fmt.Fprintln(w)
 	case ValueNode[bool]:
 		fmt.Fprint(w, prefix)
 		fmt.Fprintf(w, "boolean(%t)", n.Literal)
@@ -60,7 +64,14 @@ func debug(n Node, level int, w io.Writer) error {
 		})
 	case ObjectNode:
 		return debugNode(w, "object", prefix, func() error {
-			return nil
+			return debugList(n.List, level+1, w)
+		})
+	case PropNode:
+		return debugNode(w, "property", prefix, func() error {
+			if err := debug(n.Key, level+1, w); err != nil {
+				return err
+			}
+			return debug(n.Value, level+1, w)
 		})
 	case ExportNode:
 		return debugNode(w, "export", prefix, func() error {
@@ -85,6 +96,13 @@ func debug(n Node, level int, w io.Writer) error {
 			}
 			return debug(n.Expr, level+1, w)
 		})
+	case VarDeclNode:
+		return debugNode(w, "var", prefix, func() error {
+			if err := debug(n.Ident, level+1, w); err != nil {
+				return err
+			}
+			return debug(n.Expr, level+1, w)
+		})
 	case AssignNode:
 		return debugNode(w, "assignment", prefix, func() error {
 			if err := debug(n.Ident, level+1, w); err != nil {
@@ -100,10 +118,55 @@ func debug(n Node, level int, w io.Writer) error {
 		return debugFunc(w, n, level)
 	case CallNode:
 		return debugCall(w, n, level)
+	case NewNode:
+		return debugNode(w, "new", prefix, func() error {
+			if err := debug(n.Ident, level+1, w); err != nil {
+				return err
+			}
+			return debugArgs(n.Args, level+1, w)
+		})
+	case ThisNode:
+		fmt.Fprint(w, prefix)
+		fmt.Fprint(w, "this")
+		fmt.Fprintln(w)
+	case SuperNode:
+		fmt.Fprint(w, prefix)
+		fmt.Fprint(w, "super")
+		fmt.Fprintln(w)
+	case ClassNode:
+		return debugNode(w, fmt.Sprintf("class(%s)", n.Ident), prefix, func() error {
+			if n.Ctor != nil {
+				if err := debug(n.Ctor, level+1, w); err != nil {
+					return err
+				}
+			}
+			return debugList(n.Members, level+1, w)
+		})
+	case MethodNode:
+		return debug(n.Func, level, w)
+	case FieldNode:
+		return debugNode(w, fmt.Sprintf("field(%s)", n.Ident), prefix, func() error {
+			return debug(n.Expr, level+1, w)
+		})
 	case ReturnNode:
 		return debugNode(w, "return", prefix, func() error {
 			return debug(n.Node, level+1, w)
 		})
+	case YieldNode:
+		name := "yield"
+		if n.Delegate {
+			name = "yield*"
+		}
+		return debugNode(w, name, prefix, func() error {
+			if n.Node == nil {
+				return nil
+			}
+			return debug(n.Node, level+1, w)
+		})
+	case AwaitNode:
+		return debugNode(w, "await", prefix, func() error {
+			return debug(n.Node, level+1, w)
+		})
 	case TryNode:
 	case CatchNode:
 	case ThrowNode:
@@ -127,9 +190,9 @@ func debug(n Node, level int, w io.Writer) error {
 	case DoNode:
 	case ForNode:
 	case LabelNode:
-		fmt.Fprint(w, prefix)
-		fmt.Fprintf(w, "label(%s)", n.Ident)
-		fmt.Fprintln(w)
+		return debugNode(w, fmt.Sprintf("label(%s)", n.Ident), prefix, func() error {
+			return debug(n.Node, level+1, w)
+		})
 	case BreakNode:
 		fmt.Fprint(w, prefix)
 		if n.Label != "" {
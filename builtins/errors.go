@@ -0,0 +1,163 @@
+package builtins
+
+import (
+	"github.com/midbel/enjoy/env"
+	"github.com/midbel/enjoy/value"
+)
+
+const errorsIdent = "#errors"
+
+// errorTag marks the base Error prototype of every runtime so that errors can be
+// recognized without knowing the runtime they come from.
+var errorTag = value.CreateSymbol(value.CreateString("error"))
+
+var errorNames = []string{
+	"Error",
+	"TypeError",
+	"RangeError",
+	"ReferenceError",
+	"SyntaxError",
+	"AggregateError",
+}
+
+type errorProtos map[string]*value.Object
+
+func createErrorProtos() errorProtos {
+	base := createErrorProto("Error", nil)
+	base.DefineSymbol(errorTag, value.Descriptor{
+		Value: value.CreateBool(true),
+	})
+	protos := errorProtos{
+		"Error": base,
+	}
+	for _, name := range errorNames[1:] {
+		protos[name] = createErrorProto(name, base)
+	}
+	return protos
+}
+
+func errorProtosOf(ev env.Environ[value.Value]) errorProtos {
+	if ev != nil {
+		v, err := ev.Resolve(errorsIdent)
+		if p, ok := v.(errorProtos); err == nil && ok {
+			return p
+		}
+	}
+	return createErrorProtos()
+}
+
+func (_ errorProtos) True() bool {
+	return true
+}
+
+func (_ errorProtos) Type() string {
+	return "object"
+}
+
+func (_ errorProtos) String() string {
+	return "errors"
+}
+
+// DefineErrors creates the error constructors with their own set of prototypes
+// and registers them in ev.
+func DefineErrors(ev env.Environ[value.Value]) {
+	protos := createErrorProtos()
+	ev.Define(errorsIdent, protos, true)
+	for _, name := range errorNames {
+		ev.Define(name, createErrorCtor(name, protos[name]), true)
+	}
+}
+
+func CreateError(ev env.Environ[value.Value], name, msg string) value.Value {
+	protos := errorProtosOf(ev)
+	proto, ok := protos[name]
+	if !ok {
+		proto = protos["Error"]
+	}
+	return createError(proto, value.CreateString(msg), nil)
+}
+
+func IsError(v value.Value) bool {
+	obj, ok := v.(*value.Object)
+	if !ok {
+		return false
+	}
+	for p := obj.Prototype(); p != nil; p = p.Prototype() {
+		if _, ok := p.GetOwnSymbol(errorTag); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func createErrorCtor(name string, proto *value.Object) value.Value {
+	call := func(args ...value.Value) (value.Value, error) {
+		var (
+			msg   = value.Undefined()
+			cause value.Value
+		)
+		if len(args) >= 1 {
+			msg = args[0]
+		}
+		if len(args) >= 2 {
+			if opts, ok := args[1].(*value.Object); ok && opts.Has("cause") {
+				cause, _ = opts.Get("cause")
+			}
+		}
+		return createError(proto, msg, cause), nil
+	}
+	return value.CreateConstructor(name, call, proto)
+}
+
+func createError(proto *value.Object, msg, cause value.Value) value.Value {
+	obj := value.CreateObject(nil).(*value.Object)
+	obj.SetPrototype(proto)
+	if !value.IsUndefined(msg) {
+		obj.Define("message", hiddenProp(value.CreateString(msg.String())))
+	}
+	if cause != nil {
+		obj.Define("cause", hiddenProp(cause))
+	}
+	return obj
+}
+
+func createErrorProto(name string, parent *value.Object) *value.Object {
+	obj := value.CreateObject(nil).(*value.Object)
+	obj.SetPrototype(parent)
+	obj.Define("name", hiddenProp(value.CreateString(name)))
+	obj.Define("message", hiddenProp(value.CreateString("")))
+	if parent == nil {
+		obj.Define("toString", hiddenProp(value.CreateMethod("toString", errorToString)))
+	}
+	return obj
+}
+
+func errorToString(this value.Value, _ ...value.Value) (value.Value, error) {
+	if _, ok := this.(*value.Object); !ok {
+		return nil, value.ErrOperation
+	}
+	get := func(prop, def string) string {
+		v, err := value.Get(this, prop)
+		if err != nil || value.IsUndefined(v) {
+			return def
+		}
+		return v.String()
+	}
+	name, msg := get("name", "Error"), get("message", "")
+	switch {
+	case name == "":
+		return value.CreateString(msg), nil
+	case msg == "":
+		return value.CreateString(name), nil
+	default:
+		return value.CreateString(name + ": " + msg), nil
+	}
+}
+
+func hiddenProp(v value.Value) value.Descriptor {
+	return value.Descriptor{
+		Value:        v,
+		Writable:     true,
+		Configurable: true,
+	}
+}
@@ -1,6 +1,8 @@
 package builtins
 
 import (
+	"fmt"
+
 	"github.com/midbel/enjoy/value"
 )
 
@@ -12,6 +14,11 @@ func Object() value.Value {
 	obj.RegisterFunc("create", objectCreate)
 	obj.RegisterFunc("assign", objectAssign)
 	obj.RegisterFunc("entries", objectEntries)
+	obj.RegisterFunc("defineProperty", objectDefineProperty)
+	obj.RegisterFunc("defineProperties", objectDefineProperties)
+	obj.RegisterFunc("getOwnPropertyDescriptor", objectGetOwnPropertyDescriptor)
+	obj.RegisterFunc("getOwnPropertyDescriptors", objectGetOwnPropertyDescriptors)
+	obj.RegisterFunc("getOwnPropertyNames", objectGetOwnPropertyNames)
 	return obj
 }
 
@@ -20,7 +27,22 @@ func objectAssign(_ value.Global, args []value.Value) (value.Value, error) {
 }
 
 func objectEntries(_ value.Global, args []value.Value) (value.Value, error) {
-	return nil, nil
+	if len(args) < 1 {
+		return nil, value.ErrArgument
+	}
+	obj, ok := args[0].(*value.Object)
+	if !ok {
+		return nil, value.ErrOperation
+	}
+	var list []value.Value
+	for _, k := range obj.Enumerate() {
+		v, err := obj.Get(k.String())
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value.CreateArray([]value.Value{k, v}))
+	}
+	return value.CreateArray(list), nil
 }
 
 func objectCreate(_ value.Global, args []value.Value) (value.Value, error) {
@@ -52,3 +74,194 @@ func objectSeal(_ value.Global, args []value.Value) (value.Value, error) {
 	obj.Seal()
 	return obj, nil
 }
+
+func objectDefineProperty(_ value.Global, args []value.Value) (value.Value, error) {
+	if len(args) < 3 {
+		return nil, value.ErrArgument
+	}
+	obj, ok := args[0].(*value.Object)
+	if !ok {
+		return nil, value.ErrOperation
+	}
+	desc, ok := args[2].(*value.Object)
+	if !ok {
+		return nil, value.ErrOperation
+	}
+	return obj, defineProperty(obj, args[1], desc)
+}
+
+func objectDefineProperties(_ value.Global, args []value.Value) (value.Value, error) {
+	if len(args) < 2 {
+		return nil, value.ErrArgument
+	}
+	obj, ok := args[0].(*value.Object)
+	if !ok {
+		return nil, value.ErrOperation
+	}
+	props, ok := args[1].(*value.Object)
+	if !ok {
+		return nil, value.ErrOperation
+	}
+	for _, k := range props.Enumerate() {
+		v, err := props.Get(k.String())
+		if err != nil {
+			return nil, err
+		}
+		desc, ok := v.(*value.Object)
+		if !ok {
+			return nil, value.ErrOperation
+		}
+		if err := defineProperty(obj, k, desc); err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+func objectGetOwnPropertyDescriptor(_ value.Global, args []value.Value) (value.Value, error) {
+	if len(args) < 2 {
+		return nil, value.ErrArgument
+	}
+	obj, ok := args[0].(*value.Object)
+	if !ok {
+		return nil, value.ErrOperation
+	}
+	d, ok := getOwnProperty(obj, args[1])
+	if !ok {
+		return value.Undefined(), nil
+	}
+	return fromDescriptor(d), nil
+}
+
+func objectGetOwnPropertyDescriptors(_ value.Global, args []value.Value) (value.Value, error) {
+	if len(args) < 1 {
+		return nil, value.ErrArgument
+	}
+	obj, ok := args[0].(*value.Object)
+	if !ok {
+		return nil, value.ErrOperation
+	}
+	res := value.CreateObject(nil).(*value.Object)
+	for _, k := range obj.Names() {
+		d, _ := obj.GetOwn(k)
+		res.Set(k, fromDescriptor(d))
+	}
+	for _, sym := range obj.Symbols() {
+		d, _ := obj.GetOwnSymbol(sym)
+		res.SetSymbol(sym, fromDescriptor(d))
+	}
+	return res, nil
+}
+
+func objectGetOwnPropertyNames(_ value.Global, args []value.Value) (value.Value, error) {
+	if len(args) < 1 {
+		return nil, value.ErrArgument
+	}
+	obj, ok := args[0].(*value.Object)
+	if !ok {
+		return nil, value.ErrOperation
+	}
+	var list []value.Value
+	for _, k := range obj.Names() {
+		list = append(list, value.CreateString(k))
+	}
+	return value.CreateArray(list), nil
+}
+
+func defineProperty(obj *value.Object, key value.Value, desc *value.Object) error {
+	curr, ok := getOwnProperty(obj, key)
+	if !ok {
+		curr = value.Descriptor{
+			Value: value.Undefined(),
+		}
+	}
+	d, err := toDescriptor(desc, curr)
+	if err != nil {
+		return err
+	}
+	if sym, ok := key.(*value.Symbol); ok {
+		return obj.DefineSymbol(sym, d)
+	}
+	return obj.Define(key.String(), d)
+}
+
+func getOwnProperty(obj *value.Object, key value.Value) (value.Descriptor, bool) {
+	if sym, ok := key.(*value.Symbol); ok {
+		return obj.GetOwnSymbol(sym)
+	}
+	return obj.GetOwn(key.String())
+}
+
+func toDescriptor(obj *value.Object, d value.Descriptor) (value.Descriptor, error) {
+	get := func(prop string) (value.Value, bool) {
+		if !obj.Has(prop) {
+			return nil, false
+		}
+		v, err := obj.Get(prop)
+		return v, err == nil
+	}
+	var (
+		data     bool
+		accessor bool
+	)
+	if v, ok := get("value"); ok {
+		d.Value, d.Get, d.Set = v, nil, nil
+		data = true
+	}
+	if v, ok := get("writable"); ok {
+		d.Writable = v.True()
+		data = true
+	}
+	for _, prop := range []string{"get", "set"} {
+		v, ok := get(prop)
+		if !ok {
+			continue
+		}
+		if value.IsUndefined(v) {
+			v = nil
+		} else if _, ok := v.(value.Invoker); !ok {
+			return d, fmt.Errorf("%s: not a function: %w", prop, value.ErrOperation)
+		}
+		if prop == "get" {
+			d.Get = v
+		} else {
+			d.Set = v
+		}
+		d.Value, d.Writable = nil, false
+		accessor = true
+	}
+	if data && accessor {
+		return d, fmt.Errorf("invalid property descriptor: %w", value.ErrOperation)
+	}
+	if v, ok := get("enumerable"); ok {
+		d.Enumerable = v.True()
+	}
+	if v, ok := get("configurable"); ok {
+		d.Configurable = v.True()
+	}
+	if d.Value == nil && !d.IsAccessor() {
+		d.Value = value.Undefined()
+	}
+	return d, nil
+}
+
+func fromDescriptor(d value.Descriptor) value.Value {
+	obj := value.CreateObject(nil).(*value.Object)
+	if d.IsAccessor() {
+		obj.Set("get", accessorValue(d.Get))
+		obj.Set("set", accessorValue(d.Set))
+	} else {
+		obj.Set("value", d.Value)
+		obj.Set("writable", value.CreateBool(d.Writable))
+	}
+	obj.Set("enumerable", value.CreateBool(d.Enumerable))
+	obj.Set("configurable", value.CreateBool(d.Configurable))
+	return obj
+}
+
+func accessorValue(v value.Value) value.Value {
+	if v == nil {
+		return value.Undefined()
+	}
+	return v
+}
@@ -0,0 +1,30 @@
+package builtins
+
+import (
+	"github.com/midbel/enjoy/value"
+)
+
+func RegExp() value.Value {
+	return value.CreateBuiltin("RegExp", createRegExp)
+}
+
+func createRegExp(args ...value.Value) (value.Value, error) {
+	var pattern, flags string
+	if len(args) > 0 {
+		switch p := args[0].(type) {
+		case *value.RegExp:
+			src, _ := p.Get("source")
+			pattern = src.String()
+			fs, _ := p.Get("flags")
+			flags = fs.String()
+		default:
+			if !value.IsUndefined(p) {
+				pattern = p.String()
+			}
+		}
+	}
+	if len(args) > 1 && !value.IsUndefined(args[1]) {
+		flags = args[1].String()
+	}
+	return value.CreateRegExp(pattern, flags)
+}
@@ -0,0 +1,48 @@
+package builtins
+
+import (
+	"github.com/midbel/enjoy/value"
+)
+
+func Symbol() value.Value {
+	ctor := func(args ...value.Value) (value.Value, error) {
+		desc := value.Undefined()
+		if len(args) > 0 && !value.IsUndefined(args[0]) {
+			desc = value.CreateString(args[0].String())
+		}
+		return value.CreateSymbol(desc), nil
+	}
+	b := value.CreateFunction("Symbol", ctor)
+
+	wellKnown := map[string]*value.Symbol{
+		"iterator":      value.SymbolIterator,
+		"asyncIterator": value.SymbolAsyncIterator,
+		"toPrimitive":   value.SymbolToPrimitive,
+		"toStringTag":   value.SymbolToStringTag,
+	}
+	for name, sym := range wellKnown {
+		b.Set(name, sym)
+	}
+	b.Set("for", value.CreateBuiltin("for", symbolFor))
+	b.Set("keyFor", value.CreateBuiltin("keyFor", symbolKeyFor))
+	return b
+}
+
+func symbolFor(args ...value.Value) (value.Value, error) {
+	key := "undefined"
+	if len(args) > 0 {
+		key = args[0].String()
+	}
+	return value.SymbolFor(key), nil
+}
+
+func symbolKeyFor(args ...value.Value) (value.Value, error) {
+	if len(args) == 0 {
+		return nil, value.ErrArgument
+	}
+	sym, ok := args[0].(*value.Symbol)
+	if !ok {
+		return nil, value.ErrOperation
+	}
+	return value.SymbolKeyFor(sym), nil
+}
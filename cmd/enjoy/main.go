@@ -6,6 +6,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/midbel/enjoy/env"
 	"github.com/midbel/enjoy/eval"
 )
 
@@ -14,15 +15,8 @@ func main() {
 		trace = flag.Bool("t", false, "trace")
 	)
 	flag.Parse()
-	r, err := os.Open(flag.Arg(0))
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(2)
-	}
-	defer r.Close()
-
 	now := time.Now()
-	v, err := eval.EvalDefault(r)
+	v, err := eval.EvalFile(flag.Arg(0), env.EnclosedEnv(eval.Default()))
 	if err == nil && v != nil {
 		fmt.Println(v)
 	}
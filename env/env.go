@@ -6,12 +6,14 @@ import (
 )
 
 var (
-	ErrDefined    = errors.New("variable already defined")
-	ErrNotDefined = errors.New("variable not defined")
-	ErrAssign     = errors.New("variable can not be assigned")
+	ErrDefined       = errors.New("variable already defined")
+	ErrNotDefined    = errors.New("variable not defined")
+	ErrAssign        = errors.New("variable can not be assigned")
+	ErrUninitialized = errors.New("variable accessed before initialization")
 )
 
 type Environ[T any] interface {
+	Declare(string) error
 	Define(string, T, bool) error
 	Assign(string, T) error
 	Resolve(string) (T, error)
@@ -27,6 +29,10 @@ func Immutable[T any](env Environ[T]) Environ[T] {
 	}
 }
 
+func (_ ImmutableEnv[T]) Declare(ident string) error {
+	return fmt.Errorf("%s: %w", ident, ErrAssign)
+}
+
 func (_ ImmutableEnv[T]) Define(ident string, _ T, _ bool) error {
 	return fmt.Errorf("%s: %w", ident, ErrAssign)
 }
@@ -52,6 +58,10 @@ func EnclosedEnv[T any](parent Environ[T]) Environ[T] {
 	return &e
 }
 
+func (e *Env[T]) Parent() Environ[T] {
+	return e.parent
+}
+
 func (e *Env[T]) Clear() {
 	e.values = make(map[string]value[T])
 }
@@ -60,10 +70,20 @@ func (e *Env[T]) Delete(ident string) {
 	delete(e.values, ident)
 }
 
-func (e *Env[T]) Define(ident string, val T, ro bool) error {
+func (e *Env[T]) Declare(ident string) error {
 	if _, ok := e.values[ident]; ok {
 		return fmt.Errorf("%s: %w", ident, ErrDefined)
 	}
+	e.values[ident] = value[T]{
+		uninit: true,
+	}
+	return nil
+}
+
+func (e *Env[T]) Define(ident string, val T, ro bool) error {
+	if v, ok := e.values[ident]; ok && !v.uninit {
+		return fmt.Errorf("%s: %w", ident, ErrDefined)
+	}
 	e.values[ident] = value[T]{
 		ro:    ro,
 		value: val,
@@ -74,6 +94,9 @@ func (e *Env[T]) Define(ident string, val T, ro bool) error {
 func (e *Env[T]) Assign(ident string, val T) error {
 	v, ok := e.values[ident]
 	if ok {
+		if v.uninit {
+			return fmt.Errorf("%s: %w", ident, ErrUninitialized)
+		}
 		if v.ro {
 			return fmt.Errorf("%s: %w", ident, ErrAssign)
 		}
@@ -90,6 +113,9 @@ func (e *Env[T]) Assign(ident string, val T) error {
 func (e *Env[T]) Resolve(ident string) (T, error) {
 	v, ok := e.values[ident]
 	if ok {
+		if v.uninit {
+			return v.value, fmt.Errorf("%s: %w", ident, ErrUninitialized)
+		}
 		return v.value, nil
 	}
 	if e.parent != nil {
@@ -99,6 +125,7 @@ func (e *Env[T]) Resolve(ident string) (T, error) {
 }
 
 type value[T any] struct {
-	ro    bool
-	value T
+	ro     bool
+	uninit bool
+	value  T
 }
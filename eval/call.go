@@ -3,12 +3,15 @@ package eval
 import (
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/midbel/enjoy/ast"
 	"github.com/midbel/enjoy/env"
 	"github.com/midbel/enjoy/value"
 )
 
+const argumentsIdent = "arguments"
+
 func evalReturn(n ast.ReturnNode, ev env.Environ[value.Value]) (value.Value, error) {
 	v, err := eval(n.Node, ev)
 	if err == nil {
@@ -22,17 +25,22 @@ func evalCall(n ast.CallNode, ev env.Environ[value.Value]) (value.Value, error)
 		res value.Value
 		err error
 	)
+	if f := currentFrame(ev); f != nil {
+		f.pos = n.Position
+	}
 	switch m := n.Ident.(type) {
 	case ast.MemberNode:
-		res, err = callMember(m, n.Args, ev)
+		res, err = callMember(m, n.Args, n.Optional, ev)
+	case ast.SuperNode:
+		res, err = callSuper(n, ev)
 	default:
 		res, err = callDefault(n, ev)
 	}
 	return res, err
 }
 
-func callMember(n ast.MemberNode, args ast.Node, ev env.Environ[value.Value]) (value.Value, error) {
-	v, err := eval(n.Curr, ev)
+func callMember(n ast.MemberNode, args ast.Node, optional bool, ev env.Environ[value.Value]) (value.Value, error) {
+	v, err := evalLink(n.Curr, n.Optional, ev)
 	if err != nil {
 		return nil, err
 	}
@@ -40,14 +48,40 @@ func callMember(n ast.MemberNode, args ast.Node, ev env.Environ[value.Value]) (v
 	if !ok {
 		return nil, ErrEval
 	}
-	call, ok := v.(value.Callable)
-	if !ok {
-		return nil, value.ErrOperation
+	if optional && !isMethod(v, id.Ident) {
+		return nil, errChain
 	}
 	values, err := callArgs(args, ev)
 	if err != nil {
 		return nil, err
 	}
+	this := v
+	if _, ok := n.Curr.(ast.SuperNode); ok {
+		if this, err = evalThis(ast.ThisNode{}, ev); err != nil {
+			return nil, err
+		}
+		fn, err := getSuper(v, id.Ident, ev)
+		if err != nil {
+			return nil, err
+		}
+		if !isCallable(fn) {
+			return nil, fmt.Errorf("%s is not a function: %w", id.Ident, value.ErrOperation)
+		}
+		return callWith(fn, this, values, ev)
+	}
+	if _, ok := v.(value.Getter); ok {
+		fn, err := value.Get(v, id.Ident)
+		if err == nil && isCallable(fn) {
+			return callWith(fn, this, values, ev)
+		}
+	}
+	if isCallable(v) {
+		return callFuncMethod(v, id.Ident, values, ev)
+	}
+	call, ok := v.(value.Callable)
+	if _, obj := v.(*value.Object); !ok || obj {
+		return nil, fmt.Errorf("%s is not a function: %w", id.Ident, value.ErrOperation)
+	}
 	v, err = call.Call(id.Ident, values)
 	if err != nil {
 		err = fmt.Errorf("%s: %w", id.Ident, err)
@@ -55,8 +89,26 @@ func callMember(n ast.MemberNode, args ast.Node, ev env.Environ[value.Value]) (v
 	return v, err
 }
 
+func isMethod(v value.Value, ident string) bool {
+	if _, ok := v.(value.Getter); ok {
+		fn, err := value.Get(v, ident)
+		if err == nil && !isNullish(fn) {
+			return true
+		}
+	}
+	if isNullish(v) {
+		return false
+	}
+	if isCallable(v) {
+		return true
+	}
+	_, obj := v.(*value.Object)
+	_, ok := v.(value.Callable)
+	return ok && !obj
+}
+
 func callDefault(n ast.CallNode, ev env.Environ[value.Value]) (value.Value, error) {
-	call, err := eval(n.Ident, ev)
+	call, err := evalLink(n.Ident, n.Optional, ev)
 	if err != nil {
 		return nil, err
 	}
@@ -64,13 +116,38 @@ func callDefault(n ast.CallNode, ev env.Environ[value.Value]) (value.Value, erro
 	if err != nil {
 		return nil, err
 	}
+	res, err := callValue(call, args, ev)
+	if _, ok := call.(value.Builtin); ok && err == nil {
+		attachStack(res, ev)
+	}
+	return res, err
+}
+
+func callValue(call value.Value, args []value.Value, ev env.Environ[value.Value]) (value.Value, error) {
+	return callWith(call, value.Undefined(), args, ev)
+}
+
+func callWith(call, this value.Value, args []value.Value, ev env.Environ[value.Value]) (value.Value, error) {
 	switch call := call.(type) {
 	case value.Func:
-		return execUserFunc(call, args, ev)
+		return execUserFunc(call, this, args, ev)
 	case value.Builtin:
-		return execBuiltinFunc(call, args)
+		return execBuiltinFunc(call, this, args)
+	case *class:
+		return nil, fmt.Errorf("class %s can not be invoked without new: %w", call.Ident, value.ErrOperation)
+	case *boundFunc:
+		return callWith(call.target, call.this, call.arguments(args), ev)
 	default:
-		return nil, ErrEval
+		return nil, fmt.Errorf("%s is not a function: %w", call, value.ErrOperation)
+	}
+}
+
+func isCallable(v value.Value) bool {
+	switch v.(type) {
+	case value.Func, value.Builtin, *class, *boundFunc:
+		return true
+	default:
+		return false
 	}
 }
 
@@ -85,161 +162,188 @@ func callArgs(n ast.Node, ev env.Environ[value.Value]) ([]value.Value, error) {
 		if err != nil {
 			return nil, err
 		}
-		args = append(args, g)
+		if s, ok := g.(value.Spread); ok {
+			list, err := s.Spread()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, list...)
+		} else {
+			args = append(args, g)
+		}
 	}
 	return args, nil
 }
 
-func execBuiltinFunc(fn value.Builtin, args []value.Value) (value.Value, error) {
-	return fn.Apply(args)
+func execBuiltinFunc(fn value.Builtin, this value.Value, args []value.Value) (value.Value, error) {
+	return fn.Invoke(this, args)
 }
 
 func prepareArgs(fn value.Func, args []value.Value, ev env.Environ[value.Value]) (env.Environ[value.Value], error) {
-	var (
-		tmp = env.EnclosedEnv[value.Value](fn.Env)
-		arg value.Value
-		err error
-	)
-	for i := 0; i < len(fn.Params); i++ {
-		p := fn.Params[i]
-		if i < len(args) {
-			arg = args[i]
-		} else {
-			arg = value.Undefined()
+	tmp := env.EnclosedEnv[value.Value](fn.Env)
+	for i, p := range fn.Params {
+		if p.Rest {
+			var rest []value.Value
+			if i < len(args) {
+				rest = slices.Clone(args[i:])
+			}
+			if err := bindParam(p, value.CreateArray(rest), tmp); err != nil {
+				return nil, err
+			}
+			break
 		}
-		arg, err = argValue(p, arg, tmp)
+		arg, err := argValue(p, argAt(args, i), tmp)
 		if err != nil {
 			return nil, err
 		}
-		switch arg := arg.(type) {
-		case *value.Object:
-			err = argObject(p, arg, tmp)
-		case *value.Array:
-			err = argArray(p, arg, tmp)
-		case value.Spread:
-			for _, a := range arg.Spread() {
-				if i >= len(fn.Params) {
-					break
-				}
-				if err := tmp.Define(fn.Params[i].Name, a, false); err != nil {
-					return nil, err
-				}
-				i++
-			}
-		default:
-			err = tmp.Define(p.Name, arg, false)
+		if err := bindParam(p, arg, tmp); err != nil {
+			return nil, err
 		}
-		if err != nil {
+	}
+	if !fn.Arrow {
+		err := tmp.Define(argumentsIdent, value.CreateArray(slices.Clone(args)), false)
+		if err != nil && !errors.Is(err, env.ErrDefined) {
 			return nil, err
 		}
 	}
-	return tmp, err
+	return tmp, nil
 }
 
 func argValue(prm value.Parameter, arg value.Value, ev env.Environ[value.Value]) (value.Value, error) {
-	if !value.IsUndefined(arg) && !value.IsNull(arg) {
-		return arg, nil
-	}
-	if prm.Value == nil {
+	if !value.IsUndefined(arg) || prm.Value == nil {
 		return arg, nil
 	}
 	switch a := prm.Value.(type) {
 	case ast.AssignNode:
+		if a.Expr == nil {
+			return arg, nil
+		}
 		return eval(a.Expr, ev)
-	case ast.BindingArrayNode:
-	case ast.BindingObjectNode:
+	case ast.BindingArrayNode, ast.BindingObjectNode:
+		return arg, nil
 	default:
 		return eval(prm.Value, ev)
 	}
-	return arg, nil
 }
 
-func argArray(prm value.Parameter, arr *value.Array, ev env.Environ[value.Value]) error {
-	switch a := prm.Value.(type) {
-	case ast.AssignNode:
-		prm.Value = a.Ident
-		return argArray(prm, arr, ev)
-	case ast.BindingArrayNode:
-		return bindArray(a, arr, ev, false)
-	default:
-		if prm.Name == "" {
-			return ErrEval
-		}
-		return ev.Define(prm.Name, arr, false)
+func bindParam(prm value.Parameter, arg value.Value, ev env.Environ[value.Value]) error {
+	if prm.Name != "" {
+		return ev.Define(prm.Name, arg, false)
 	}
-	return nil
-}
-
-func argObject(prm value.Parameter, obj *value.Object, ev env.Environ[value.Value]) error {
-	switch a := prm.Value.(type) {
-	case ast.AssignNode:
-		prm.Value = a.Ident
-		return argObject(prm, obj, ev)
-	case ast.BindingObjectNode:
-		return bindObject(a, obj, ev, false)
-	default:
-		if prm.Name == "" {
-			return ErrEval
-		}
-		return ev.Define(prm.Name, obj, false)
+	n := prm.Value
+	if a, ok := n.(ast.AssignNode); ok {
+		n = a.Ident
 	}
-	return nil
+	return bindValue(n, arg, ev, false)
 }
 
-func execUserFunc(fn value.Func, args []value.Value, ev env.Environ[value.Value]) (value.Value, error) {
+func execUserFunc(fn value.Func, this value.Value, args []value.Value, ev env.Environ[value.Value]) (value.Value, error) {
 	tmp, err := prepareArgs(fn, args, ev)
 	if err != nil {
 		return nil, err
 	}
-	res, err := eval(fn.Body, tmp)
+	if !fn.Arrow {
+		tmp.Define(thisIdent, this, true)
+	}
+	frame := enterFrame(fn.Ident, tmp, ev)
+	if err := hoistVars(fn.Body, frame); err != nil {
+		return nil, err
+	}
+	if fn.Async {
+		return execAsyncFunc(fn, frame)
+	}
+	if fn.Generator {
+		return createGenerator(fn, frame), nil
+	}
+	res, err := eval(fn.Body, frame)
 	if errors.Is(err, ErrReturn) {
 		err = nil
 	}
-	return res, err
+	return res, throwError(err, frame)
 }
 
 func evalArrow(n ast.ArrowNode, ev env.Environ[value.Value]) (value.Value, error) {
 	fn := value.Func{
-		Body: EvaluableNode(n.Body),
-		Env:  ev,
+		Body:  EvaluableNode(n.Body),
+		Env:   ev,
+		Arrow: true,
+		Async: n.Async,
+		Props: value.CreateObject(nil).(*value.Object),
 	}
-	switch n := n.Args.(type) {
-	case ast.VarNode:
-		p := value.Parameter{
-			Name: n.Ident,
-		}
-		fn.Params = append(fn.Params, p)
-	case ast.SeqNode:
-		for _, a := range n.Nodes {
-			var p value.Parameter
-			g, ok := a.(ast.VarNode)
-			if !ok {
-				return nil, ErrEval
-			}
-			p.Name = g.Ident
-			fn.Params = append(fn.Params, p)
-		}
-	default:
-		return nil, ErrEval
+	params, err := createParams(n.Args)
+	if err != nil {
+		return nil, err
 	}
+	fn.Params = params
 	return fn, nil
 }
 
 func evalFunc(n ast.FuncNode, ev env.Environ[value.Value]) (value.Value, error) {
+	fn, err := createFuncProto(n, ev)
+	if err != nil {
+		return nil, err
+	}
+	if fn.Ident != "" {
+		if err := ev.Define(fn.Ident, fn, false); err != nil {
+			return nil, err
+		}
+	}
+	return fn, nil
+}
+
+func createFuncProto(n ast.FuncNode, ev env.Environ[value.Value]) (value.Func, error) {
+	fn, err := createFunc(n, ev)
+	if err != nil {
+		return fn, err
+	}
+	proto := value.CreateObject(nil).(*value.Object)
+	proto.Define("constructor", value.Descriptor{
+		Value:        fn,
+		Writable:     true,
+		Configurable: true,
+	})
+	fn.Props.Define("prototype", value.Descriptor{
+		Value:    proto,
+		Writable: true,
+	})
+	return fn, nil
+}
+
+func createFunc(n ast.FuncNode, ev env.Environ[value.Value]) (value.Func, error) {
 	fn := value.Func{
-		Ident: n.Ident,
-		Body:  EvaluableNode(n.Body),
-		Env:   ev,
+		Ident:     n.Ident,
+		Body:      EvaluableNode(n.Body),
+		Env:       ev,
+		Generator: n.Generator,
+		Async:     n.Async,
+		Props:     value.CreateObject(nil).(*value.Object),
 	}
-	seq, ok := n.Args.(ast.SeqNode)
-	if !ok {
-		return nil, ErrEval
+	params, err := createParams(n.Args)
+	if err != nil {
+		return fn, err
 	}
-	for _, a := range seq.Nodes {
+	fn.Params = params
+	return fn, nil
+}
+
+func createParams(n ast.Node) ([]value.Parameter, error) {
+	nodes := []ast.Node{n}
+	if seq, ok := n.(ast.SeqNode); ok {
+		nodes = seq.Nodes
+	}
+	var list []value.Parameter
+	for i, a := range nodes {
 		var p value.Parameter
+		if s, ok := a.(ast.SpreadNode); ok {
+			if i < len(nodes)-1 {
+				return nil, fmt.Errorf("rest parameter must be last: %w", ErrEval)
+			}
+			p.Rest = true
+			a = s.Node
+		}
 		switch g := a.(type) {
 		case ast.AssignNode:
-			if i, ok := g.Ident.(ast.VarNode); ok {
+			if i, ok := g.Ident.(ast.VarNode); ok && !p.Rest {
 				p.Name = i.Ident
 				p.Value = g.Expr
 			} else {
@@ -252,12 +356,7 @@ func evalFunc(n ast.FuncNode, ev env.Environ[value.Value]) (value.Value, error)
 		default:
 			return nil, ErrEval
 		}
-		fn.Params = append(fn.Params, p)
+		list = append(list, p)
 	}
-	if fn.Ident != "" {
-		if err := ev.Define(fn.Ident, fn, false); err != nil {
-			return nil, err
-		}
-	}
-	return fn, nil
+	return list, nil
 }
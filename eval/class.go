@@ -0,0 +1,397 @@
+package eval
+
+import (
+	"fmt"
+
+	"github.com/midbel/enjoy/ast"
+	"github.com/midbel/enjoy/env"
+	"github.com/midbel/enjoy/value"
+)
+
+const (
+	thisIdent  = "this"
+	superIdent = "super"
+	classIdent = "#class"
+)
+
+type class struct {
+	value.Func
+	parent value.Value
+	fields []ast.FieldNode
+}
+
+func (c *class) String() string {
+	return fmt.Sprintf("class %s", c.Ident)
+}
+
+func (c *class) Invoke(_ value.Value, _ []value.Value) (value.Value, error) {
+	return nil, fmt.Errorf("class %s can not be invoked without new: %w", c.Ident, value.ErrOperation)
+}
+
+func (c *class) construct(args []value.Value, ev env.Environ[value.Value]) (value.Value, error) {
+	this := createInstance(c)
+	return this, c.initialize(this, args, ev)
+}
+
+func (c *class) initialize(this *value.Object, args []value.Value, ev env.Environ[value.Value]) error {
+	if c.parent == nil {
+		if err := c.initFields(this); err != nil {
+			return err
+		}
+	}
+	if c.Body == nil {
+		if c.parent == nil {
+			return nil
+		}
+		return c.initParent(this, args, ev)
+	}
+	_, err := execUserFunc(c.Func, this, args, ev)
+	return err
+}
+
+func (c *class) initParent(this *value.Object, args []value.Value, ev env.Environ[value.Value]) error {
+	switch p := c.parent.(type) {
+	case *class:
+		if err := p.initialize(this, args, ev); err != nil {
+			return err
+		}
+	case value.Func:
+		if _, err := execUserFunc(p, this, args, ev); err != nil {
+			return err
+		}
+	case value.Builtin:
+		res, err := execBuiltinFunc(p, this, args)
+		if err != nil {
+			return err
+		}
+		attachStack(res, ev)
+		if obj, ok := res.(*value.Object); ok {
+			for _, k := range obj.Names() {
+				d, _ := obj.GetOwn(k)
+				this.Define(k, d)
+			}
+		}
+	default:
+		return fmt.Errorf("%s is not a constructor: %w", c.parent, value.ErrOperation)
+	}
+	return c.initFields(this)
+}
+
+func (c *class) initFields(this *value.Object) error {
+	if len(c.fields) == 0 {
+		return nil
+	}
+	scope := env.EnclosedEnv(c.Env)
+	scope.Define(thisIdent, this, true)
+	for _, f := range c.fields {
+		val := value.Undefined()
+		if f.Expr != nil {
+			v, err := eval(f.Expr, scope)
+			if err != nil {
+				return err
+			}
+			val = v
+		}
+		if err := this.Set(f.Ident, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func evalClass(n ast.ClassNode, ev env.Environ[value.Value]) (value.Value, error) {
+	var (
+		parent value.Value
+		super  = value.Undefined()
+		proto  = value.CreateObject(nil).(*value.Object)
+	)
+	if n.Parent != nil {
+		p, err := eval(n.Parent, ev)
+		if err != nil {
+			return nil, err
+		}
+		if !isConstructor(p) {
+			return nil, fmt.Errorf("class extends %s: not a constructor: %w", p, value.ErrOperation)
+		}
+		parent = p
+		if super, err = value.Get(p, "prototype"); err != nil {
+			return nil, err
+		}
+		if p, ok := super.(*value.Object); ok {
+			proto.SetPrototype(p)
+		}
+	}
+	var (
+		protoEnv  = env.EnclosedEnv(ev)
+		staticEnv = env.EnclosedEnv(protoEnv)
+		cls       = class{
+			parent: parent,
+		}
+	)
+	cls.Func = value.Func{
+		Ident: n.Ident,
+		Env:   protoEnv,
+		Props: value.CreateObject(nil).(*value.Object),
+	}
+	if props := superProps(parent); props != nil {
+		cls.Props.SetPrototype(props)
+	}
+	if n.Ctor != nil {
+		ctor, ok := n.Ctor.(ast.FuncNode)
+		if !ok {
+			return nil, ErrEval
+		}
+		fn, err := createFunc(ctor, protoEnv)
+		if err != nil {
+			return nil, err
+		}
+		cls.Params = fn.Params
+		cls.Body = fn.Body
+	}
+	cls.Props.Define("prototype", value.Descriptor{
+		Value: proto,
+	})
+	proto.Define("constructor", value.Descriptor{
+		Value:        &cls,
+		Writable:     true,
+		Configurable: true,
+	})
+	protoEnv.Define(classIdent, &cls, true)
+	if parent != nil {
+		protoEnv.Define(superIdent, super, true)
+		staticEnv.Define(superIdent, parent, true)
+	}
+	if n.Ident != "" {
+		protoEnv.Define(n.Ident, &cls, true)
+	}
+	var statics []ast.FieldNode
+	for _, m := range n.Members {
+		switch m := m.(type) {
+		case ast.MethodNode:
+			var (
+				scope  = protoEnv
+				target = proto
+			)
+			if m.Static {
+				scope, target = staticEnv, cls.Props
+			}
+			fn, ok := m.Func.(ast.FuncNode)
+			if !ok {
+				return nil, ErrEval
+			}
+			key, err := propKey(m.Ident, m.Key, scope)
+			if err != nil {
+				return nil, err
+			}
+			fn.Ident = keyName(key)
+			method, err := createFunc(fn, scope)
+			if err != nil {
+				return nil, err
+			}
+			defineKey(target, key, value.Descriptor{
+				Value:        method,
+				Writable:     true,
+				Configurable: true,
+			})
+		case ast.AccessorNode:
+			var (
+				scope  = protoEnv
+				target = proto
+			)
+			if m.Static {
+				scope, target = staticEnv, cls.Props
+			}
+			if err := defineAccessor(target, m, false, scope); err != nil {
+				return nil, err
+			}
+		case ast.FieldNode:
+			if m.Static {
+				statics = append(statics, m)
+			} else {
+				cls.fields = append(cls.fields, m)
+			}
+		default:
+			return nil, ErrEval
+		}
+	}
+	scope := env.EnclosedEnv(staticEnv)
+	scope.Define(thisIdent, &cls, true)
+	for _, f := range statics {
+		val := value.Undefined()
+		if f.Expr != nil {
+			v, err := eval(f.Expr, scope)
+			if err != nil {
+				return nil, err
+			}
+			val = v
+		}
+		if err := cls.Props.Set(f.Ident, val); err != nil {
+			return nil, err
+		}
+	}
+	if n.Ident != "" {
+		if err := ev.Define(n.Ident, &cls, false); err != nil {
+			return nil, err
+		}
+	}
+	return &cls, nil
+}
+
+func evalNew(n ast.NewNode, ev env.Environ[value.Value]) (value.Value, error) {
+	if f := currentFrame(ev); f != nil {
+		f.pos = n.Position
+	}
+	ctor, err := eval(n.Ident, ev)
+	if err != nil {
+		return nil, err
+	}
+	args, err := callArgs(n.Args, ev)
+	if err != nil {
+		return nil, err
+	}
+	return construct(ctor, args, ev)
+}
+
+func construct(ctor value.Value, args []value.Value, ev env.Environ[value.Value]) (value.Value, error) {
+	switch c := ctor.(type) {
+	case *class:
+		return c.construct(args, ev)
+	case value.Func:
+		if c.Arrow || c.Generator || c.Async {
+			break
+		}
+		this := createInstance(c)
+		res, err := execUserFunc(c, this, args, ev)
+		if err != nil {
+			return nil, err
+		}
+		if isObject(res) {
+			return res, nil
+		}
+		return this, nil
+	case value.Builtin:
+		if !c.Constructible() {
+			break
+		}
+		res, err := execBuiltinFunc(c, value.Undefined(), args)
+		if err == nil {
+			attachStack(res, ev)
+		}
+		return res, err
+	case *boundFunc:
+		return construct(c.target, c.arguments(args), ev)
+	}
+	return nil, fmt.Errorf("%s is not a constructor: %w", ctor, value.ErrOperation)
+}
+
+func callSuper(n ast.CallNode, ev env.Environ[value.Value]) (value.Value, error) {
+	c, err := ev.Resolve(classIdent)
+	if err != nil {
+		return nil, fmt.Errorf("super: %w", ErrEval)
+	}
+	cls, ok := c.(*class)
+	if !ok || cls.parent == nil {
+		return nil, fmt.Errorf("super: %w", ErrEval)
+	}
+	this, err := evalThis(ast.ThisNode{}, ev)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := this.(*value.Object)
+	if !ok {
+		return nil, fmt.Errorf("super: %w", ErrEval)
+	}
+	args, err := callArgs(n.Args, ev)
+	if err != nil {
+		return nil, err
+	}
+	return value.Undefined(), cls.initParent(obj, args, ev)
+}
+
+func evalSuper(_ ast.SuperNode, ev env.Environ[value.Value]) (value.Value, error) {
+	v, err := ev.Resolve(superIdent)
+	if err != nil {
+		return nil, fmt.Errorf("super: %w", ErrEval)
+	}
+	return v, nil
+}
+
+func superProps(super value.Value) *value.Object {
+	switch s := super.(type) {
+	case *value.Object:
+		return s
+	case *class:
+		return s.Props
+	case value.Func:
+		return s.Props
+	default:
+		return nil
+	}
+}
+
+func getSuper(super value.Value, prop string, ev env.Environ[value.Value]) (value.Value, error) {
+	obj := superProps(super)
+	if obj == nil {
+		return value.Get(super, prop)
+	}
+	this, err := evalThis(ast.ThisNode{}, ev)
+	if err != nil {
+		return nil, err
+	}
+	return obj.GetFrom(prop, this)
+}
+
+func setSuper(super value.Value, prop string, val value.Value, ev env.Environ[value.Value]) error {
+	obj := superProps(super)
+	if obj == nil {
+		return value.Set(super, prop, val)
+	}
+	this, err := evalThis(ast.ThisNode{}, ev)
+	if err != nil {
+		return err
+	}
+	return obj.SetFrom(prop, val, this)
+}
+
+func evalThis(_ ast.ThisNode, ev env.Environ[value.Value]) (value.Value, error) {
+	v, err := ev.Resolve(thisIdent)
+	if err != nil {
+		return value.Undefined(), nil
+	}
+	return v, nil
+}
+
+func createInstance(ctor value.Value) *value.Object {
+	obj := value.CreateObject(nil).(*value.Object)
+	if p, err := value.Get(ctor, "prototype"); err == nil {
+		if proto, ok := p.(*value.Object); ok {
+			obj.SetPrototype(proto)
+		}
+	}
+	return obj
+}
+
+func isConstructor(v value.Value) bool {
+	switch v := v.(type) {
+	case *class:
+		return true
+	case value.Func:
+		return !v.Arrow && !v.Generator && !v.Async
+	case value.Builtin:
+		p, err := v.Get("prototype")
+		return err == nil && !value.IsUndefined(p)
+	case *boundFunc:
+		return isConstructor(v.target)
+	default:
+		return false
+	}
+}
+
+func isObject(v value.Value) bool {
+	switch v.(type) {
+	case *value.Object, *value.Array, *class, value.Func, *boundFunc:
+		return true
+	default:
+		return false
+	}
+}
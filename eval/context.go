@@ -1,26 +1,298 @@
 package eval
 
 import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/midbel/enjoy/env"
+	"github.com/midbel/enjoy/parser"
 	"github.com/midbel/enjoy/value"
 )
 
+type binding func() (value.Value, error)
+
+func constBinding(v value.Value) binding {
+	return func() (value.Value, error) {
+		return v, nil
+	}
+}
+
 type context struct {
 	env.Environ[value.Value]
-	modules map[string]Module
+	module   *Module
+	imports  map[string]binding
+	modules  map[string]*Module
+	globals  env.Environ[value.Value]
+	resolver Resolver
 }
 
-func defaultContext(ev env.Environ[value.Value]) *context {
+func defaultContext(ev env.Environ[value.Value], res Resolver) *context {
 	return &context{
-		Environ: ev,
-		modules: make(map[string]Module),
+		Environ:  ev,
+		module:   createModule(""),
+		imports:  make(map[string]binding),
+		modules:  make(map[string]*Module),
+		globals:  ev,
+		resolver: res,
+	}
+}
+
+func (c *context) enclosed(mod *Module) *context {
+	ctx := context{
+		Environ:  env.EnclosedEnv(c.globals),
+		module:   mod,
+		imports:  make(map[string]binding),
+		modules:  c.modules,
+		globals:  c.globals,
+		resolver: c.resolver,
+	}
+	mod.scope = &ctx
+	return &ctx
+}
+
+func currentContext(ev env.Environ[value.Value]) (*context, error) {
+	for ev != nil {
+		switch e := ev.(type) {
+		case *context:
+			return e, nil
+		case *frame:
+			ev = e.Environ
+		case interface {
+			Parent() env.Environ[value.Value]
+		}:
+			ev = e.Parent()
+		default:
+			ev = nil
+		}
 	}
+	return nil, fmt.Errorf("module context not found: %w", ErrEval)
+}
+
+func (c *context) Define(ident string, v value.Value, ro bool) error {
+	if _, ok := c.imports[ident]; ok {
+		return fmt.Errorf("%s: %w", ident, env.ErrDefined)
+	}
+	return c.Environ.Define(ident, v, ro)
+}
+
+func (c *context) Assign(ident string, v value.Value) error {
+	if _, ok := c.imports[ident]; ok {
+		return fmt.Errorf("%s: %w", ident, env.ErrAssign)
+	}
+	return c.Environ.Assign(ident, v)
+}
+
+func (c *context) Resolve(ident string) (value.Value, error) {
+	if b, ok := c.imports[ident]; ok {
+		return b()
+	}
+	return c.Environ.Resolve(ident)
+}
+
+func (c *context) Import(ident string, mod *Module, name string) error {
+	if _, ok := c.imports[ident]; ok {
+		return fmt.Errorf("%s: %w", ident, env.ErrDefined)
+	}
+	if mod.loaded && !mod.Has(name) {
+		return fmt.Errorf("%s: %s: %w", mod.Name, name, ErrExport)
+	}
+	c.imports[ident] = remoteBinding(name, mod)
+	return nil
+}
+
+func (c *context) ImportNamespace(ident string, mod *Module) error {
+	if _, ok := c.imports[ident]; ok {
+		return fmt.Errorf("%s: %w", ident, env.ErrDefined)
+	}
+	c.imports[ident] = constBinding(mod.Namespace())
+	return nil
+}
+
+func (c *context) Load(spec string) (*Module, error) {
+	name, err := c.resolver.Resolve(spec, c.module.Name)
+	if err != nil {
+		return nil, err
+	}
+	if mod, ok := c.modules[name]; ok {
+		return mod, nil
+	}
+	mod, _, err := c.evaluate(name)
+	return mod, err
+}
+
+func (c *context) evaluate(name string) (*Module, value.Value, error) {
+	src, err := c.resolver.Load(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	mod := createModule(name)
+	if src.Exports != nil {
+		for k, v := range src.Exports {
+			mod.exports[k] = constBinding(v)
+		}
+		mod.loaded = true
+		c.modules[name] = mod
+		return mod, value.Undefined(), nil
+	}
+	n, err := parser.Parse(bytes.NewReader(src.Code))
+	if err != nil {
+		return nil, nil, err
+	}
+	c.modules[name] = mod
+
+	root := enterFrame("", c.enclosed(mod), nil)
+	if err := hoistVars(n, root); err != nil {
+		delete(c.modules, name)
+		return nil, nil, err
+	}
+	res, err := evalProgram(n, root)
+	if err != nil {
+		delete(c.modules, name)
+		return nil, nil, throwError(err, root)
+	}
+	mod.loaded = true
+	return mod, res, nil
 }
 
 type Module struct {
+	Name string
+
+	scope   env.Environ[value.Value]
+	exports map[string]binding
+	stars   []*Module
+	loaded  bool
+}
+
+func createModule(name string) *Module {
+	return &Module{
+		Name:    name,
+		exports: make(map[string]binding),
+	}
 }
 
-func Load() Module {
-	var mod Module
-	return mod
-}
\ No newline at end of file
+func (m *Module) Export(name string, b binding) error {
+	if _, ok := m.exports[name]; ok {
+		return fmt.Errorf("%s: duplicate export: %w", name, env.ErrDefined)
+	}
+	m.exports[name] = b
+	return nil
+}
+
+func (m *Module) ExportAll(mod *Module) {
+	m.stars = append(m.stars, mod)
+}
+
+func (m *Module) Has(name string) bool {
+	_, ok := m.lookup(name, make(map[*Module]struct{}))
+	return ok
+}
+
+func (m *Module) Resolve(name string) (value.Value, error) {
+	b, ok := m.lookup(name, make(map[*Module]struct{}))
+	if !ok {
+		return nil, fmt.Errorf("%s: %s: %w", m.Name, name, ErrExport)
+	}
+	return b()
+}
+
+func (m *Module) Names() []string {
+	var list []string
+	m.names(&list, make(map[*Module]struct{}))
+	slices.Sort(list)
+	return slices.Compact(list)
+}
+
+func (m *Module) Namespace() value.Value {
+	return namespace{
+		Module: m,
+	}
+}
+
+func (m *Module) lookup(name string, seen map[*Module]struct{}) (binding, bool) {
+	if _, ok := seen[m]; ok {
+		return nil, false
+	}
+	seen[m] = struct{}{}
+	if b, ok := m.exports[name]; ok {
+		return b, true
+	}
+	if name == "default" {
+		return nil, false
+	}
+	for _, s := range m.stars {
+		if b, ok := s.lookup(name, seen); ok {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+func (m *Module) names(list *[]string, seen map[*Module]struct{}) {
+	if _, ok := seen[m]; ok {
+		return
+	}
+	seen[m] = struct{}{}
+	for n := range m.exports {
+		*list = append(*list, n)
+	}
+	for _, s := range m.stars {
+		var others []string
+		s.names(&others, seen)
+		for _, n := range others {
+			if n != "default" {
+				*list = append(*list, n)
+			}
+		}
+	}
+}
+
+type namespace struct {
+	*Module
+}
+
+func (n namespace) Get(prop string) (value.Value, error) {
+	if !n.Has(prop) {
+		return value.Undefined(), nil
+	}
+	return n.Resolve(prop)
+}
+
+func (n namespace) Set(prop string, _ value.Value) error {
+	return fmt.Errorf("%s: %w", prop, value.ErrOperation)
+}
+
+func (n namespace) Enumerate() []value.Value {
+	var list []value.Value
+	for _, k := range n.Names() {
+		list = append(list, value.CreateString(k))
+	}
+	return list
+}
+
+func (_ namespace) True() bool {
+	return true
+}
+
+func (_ namespace) Type() string {
+	return "object"
+}
+
+func (n namespace) String() string {
+	var str strings.Builder
+	str.WriteRune('{')
+	for i, k := range n.Names() {
+		if i > 0 {
+			str.WriteRune(',')
+			str.WriteRune(' ')
+		}
+		str.WriteString(k)
+		str.WriteRune(':')
+		if v, err := n.Resolve(k); err == nil {
+			str.WriteString(v.String())
+		}
+	}
+	str.WriteRune('}')
+	return str.String()
+}
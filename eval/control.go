@@ -2,41 +2,239 @@ package eval
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/midbel/enjoy/ast"
 	"github.com/midbel/enjoy/env"
 	"github.com/midbel/enjoy/value"
 )
 
+type controlError struct {
+	Label string
+	Err   error
+}
+
+func breakLabel(label string) error {
+	if label == "" {
+		return ErrBreak
+	}
+	return controlError{
+		Label: label,
+		Err:   ErrBreak,
+	}
+}
+
+func continueLabel(label string) error {
+	if label == "" {
+		return ErrContinue
+	}
+	return controlError{
+		Label: label,
+		Err:   ErrContinue,
+	}
+}
+
+func (e controlError) Error() string {
+	return fmt.Sprintf("%s %s", e.Err, e.Label)
+}
+
+func (e controlError) Unwrap() error {
+	return e.Err
+}
+
+func loopControl(err error, label string) (bool, error) {
+	var ctrl controlError
+	if errors.As(err, &ctrl) && ctrl.Label != label {
+		return true, err
+	}
+	switch {
+	case errors.Is(err, ErrBreak):
+		return true, nil
+	case errors.Is(err, ErrContinue):
+		return false, nil
+	default:
+		return true, err
+	}
+}
+
 func evalLabel(n ast.LabelNode, ev env.Environ[value.Value]) (value.Value, error) {
-	return nil, nil
+	var (
+		res value.Value
+		err error
+	)
+	switch x := n.Node.(type) {
+	case ast.ForNode:
+		res, err = evalFor(x, n.Ident, ev)
+	case ast.LoopNode:
+		res, err = evalLoop(x, n.Ident, ev)
+	case ast.WhileNode:
+		res, err = evalWhile(x, n.Ident, ev)
+	case ast.DoNode:
+		res, err = evalDo(x, n.Ident, ev)
+	default:
+		res, err = eval(n.Node, ev)
+	}
+	var ctrl controlError
+	if errors.As(err, &ctrl) && ctrl.Label == n.Ident {
+		if errors.Is(err, ErrContinue) {
+			return nil, fmt.Errorf("%s: %w", n.Ident, ErrEval)
+		}
+		err = nil
+	}
+	return res, err
 }
 
-func evalLoop(n ast.LoopNode, ev env.Environ[value.Value]) (value.Value, error) {
+func evalLoop(n ast.LoopNode, label string, ev env.Environ[value.Value]) (value.Value, error) {
 	switch n.Iter.(type) {
 	case ast.IterInNode:
-		return evalForIn(n, ev)
+		return evalForIn(n, label, ev)
 	case ast.IterOfNode:
-		return evalForOf(n, ev)
+		return evalForOf(n, label, ev)
 	default:
 		return nil, ErrEval
 	}
 }
 
-func evalForIn(n ast.LoopNode, ev env.Environ[value.Value]) (value.Value, error) {
-	return nil, nil
+func evalForIn(n ast.LoopNode, label string, ev env.Environ[value.Value]) (value.Value, error) {
+	it, ok := n.Iter.(ast.IterInNode)
+	if !ok {
+		return nil, ErrEval
+	}
+	v, err := eval(it.Iter, ev)
+	if err != nil {
+		return nil, err
+	}
+	if value.IsNull(v) || value.IsUndefined(v) {
+		return nil, nil
+	}
+	var keys []value.Value
+	switch v := v.(type) {
+	case *value.Object:
+		keys = forInKeys(v)
+	case value.Enumerable:
+		keys = v.Enumerate()
+	default:
+		return nil, nil
+	}
+	var res value.Value
+	for _, k := range keys {
+		scope := env.EnclosedEnv(ev)
+		if err := bindIter(it.Ident, k, scope); err != nil {
+			return nil, err
+		}
+		res, err = eval(n.Body, env.EnclosedEnv(scope))
+		if err != nil {
+			if stop, err := loopControl(err, label); stop {
+				return res, err
+			}
+		}
+	}
+	return res, nil
 }
 
-func evalForOf(n ast.LoopNode, ev env.Environ[value.Value]) (value.Value, error) {
-	return nil, nil
+// forInKeys lists the enumerable keys of obj followed by the ones inherited
+// from its prototypes. A key shadowed by an own property is visited once.
+func forInKeys(obj *value.Object) []value.Value {
+	var (
+		list []value.Value
+		seen = make(map[string]struct{})
+	)
+	for o := obj; o != nil; o = o.Prototype() {
+		for _, k := range o.Names() {
+			if _, ok := seen[k]; ok {
+				continue
+			}
+			seen[k] = struct{}{}
+			if d, _ := o.GetOwn(k); d.Enumerable {
+				list = append(list, value.CreateString(k))
+			}
+		}
+	}
+	return list
+}
+
+func evalForOf(n ast.LoopNode, label string, ev env.Environ[value.Value]) (value.Value, error) {
+	it, ok := n.Iter.(ast.IterOfNode)
+	if !ok {
+		return nil, ErrEval
+	}
+	v, err := eval(it.Iter, ev)
+	if err != nil {
+		return nil, err
+	}
+	iter, err := getIterator(v, ev)
+	if err != nil {
+		return nil, err
+	}
+	var res value.Value
+	for {
+		v, done, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			break
+		}
+		scope := env.EnclosedEnv(ev)
+		if err := bindIter(it.Ident, v, scope); err != nil {
+			closeIterator(iter)
+			return nil, err
+		}
+		res, err = eval(n.Body, env.EnclosedEnv(scope))
+		if err != nil {
+			if stop, err := loopControl(err, label); stop {
+				if cerr := closeIterator(iter); err == nil {
+					err = cerr
+				}
+				return res, err
+			}
+		}
+	}
+	return res, nil
+}
+
+func bindIter(n ast.Node, v value.Value, ev env.Environ[value.Value]) error {
+	switch n := n.(type) {
+	case ast.LetNode:
+		return bindValue(n.Ident, v, ev, false)
+	case ast.ConstNode:
+		return bindValue(n.Ident, v, ev, true)
+	case ast.VarDeclNode:
+		return assignVar(n.Ident, v, ev)
+	default:
+		return assignValue(n, v, ev)
+	}
 }
 
 func evalBlock(n ast.BlockNode, ev env.Environ[value.Value]) (value.Value, error) {
+	if err := hoistBlock(n.Nodes, ev); err != nil {
+		return nil, err
+	}
+	return evalNodes(n.Nodes, ev)
+}
+
+// evalProgram runs the top level statements of a script or module once its
+// imports and exports are linked.
+func evalProgram(n ast.Node, ev env.Environ[value.Value]) (value.Value, error) {
+	nodes := []ast.Node{n}
+	if b, ok := n.(ast.BlockNode); ok {
+		nodes = b.Nodes
+	}
+	if err := linkModule(nodes, ev); err != nil {
+		return nil, err
+	}
+	return evalNodes(nodes, ev)
+}
+
+func evalNodes(nodes []ast.Node, ev env.Environ[value.Value]) (value.Value, error) {
 	var (
 		res value.Value
 		err error
 	)
-	for _, n := range n.Nodes {
+	for _, n := range nodes {
+		if isDeclaration(n) {
+			continue
+		}
 		res, err = eval(n, ev)
 		if err != nil {
 			break
@@ -45,56 +243,109 @@ func evalBlock(n ast.BlockNode, ev env.Environ[value.Value]) (value.Value, error
 	return res, err
 }
 
-func evalFor(n ast.ForNode, ev env.Environ[value.Value]) (value.Value, error) {
-	return nil, nil
-}
-
-func evalDo(n ast.DoNode, ev env.Environ[value.Value]) (value.Value, error) {
+func evalFor(n ast.ForNode, label string, ev env.Environ[value.Value]) (value.Value, error) {
 	var (
-		res value.Value
-		err error
+		scope = env.EnclosedEnv(ev)
+		names []string
+		res   value.Value
+		err   error
 	)
+	if n.Init != nil {
+		if _, err = eval(n.Init, scope); err != nil {
+			return nil, err
+		}
+		if let, ok := n.Init.(ast.LetNode); ok {
+			names = bindingNames(let.Ident)
+		}
+	}
+	if scope, err = copyBindings(names, scope, ev); err != nil {
+		return nil, err
+	}
 	for {
-		res, err = eval(n.Body, env.EnclosedEnv(ev))
-		if err != nil && !errors.Is(err, ErrContinue) {
-			break
+		if n.Cdt != nil {
+			v, err := eval(n.Cdt, scope)
+			if err != nil {
+				return nil, err
+			}
+			if !v.True() {
+				break
+			}
 		}
-		v, err := eval(n.Cdt, ev)
+		res, err = eval(n.Body, env.EnclosedEnv(scope))
 		if err != nil {
+			if stop, err := loopControl(err, label); stop {
+				return res, err
+			}
+		}
+		if scope, err = copyBindings(names, scope, ev); err != nil {
 			return nil, err
 		}
-		if !v.True() {
-			break
+		if n.Incr != nil {
+			if _, err = eval(n.Incr, scope); err != nil {
+				return nil, err
+			}
 		}
 	}
-	if errors.Is(err, ErrBreak) {
-		err = nil
+	return res, nil
+}
+
+func copyBindings(names []string, from, parent env.Environ[value.Value]) (env.Environ[value.Value], error) {
+	if len(names) == 0 {
+		return from, nil
 	}
-	return res, err
+	scope := env.EnclosedEnv(parent)
+	for _, n := range names {
+		v, err := from.Resolve(n)
+		if err != nil {
+			return nil, err
+		}
+		if err := scope.Define(n, v, false); err != nil {
+			return nil, err
+		}
+	}
+	return scope, nil
 }
 
-func evalWhile(n ast.WhileNode, ev env.Environ[value.Value]) (value.Value, error) {
-	var (
-		res value.Value
-		err error
-	)
+func evalDo(n ast.DoNode, label string, ev env.Environ[value.Value]) (value.Value, error) {
+	var res value.Value
 	for {
-		v, err := eval(n.Cdt, ev)
+		v, err := eval(n.Body, env.EnclosedEnv(ev))
+		if err != nil {
+			if stop, err := loopControl(err, label); stop {
+				return v, err
+			}
+		}
+		res = v
+		c, err := eval(n.Cdt, ev)
 		if err != nil {
 			return nil, err
 		}
-		if !v.True() {
+		if !c.True() {
 			break
 		}
-		res, err = eval(n.Body, env.EnclosedEnv(ev))
-		if err != nil && !errors.Is(err, ErrContinue) {
+	}
+	return res, nil
+}
+
+func evalWhile(n ast.WhileNode, label string, ev env.Environ[value.Value]) (value.Value, error) {
+	var res value.Value
+	for {
+		c, err := eval(n.Cdt, ev)
+		if err != nil {
+			return nil, err
+		}
+		if !c.True() {
 			break
 		}
+		v, err := eval(n.Body, env.EnclosedEnv(ev))
+		if err != nil {
+			if stop, err := loopControl(err, label); stop {
+				return v, err
+			}
+		}
+		res = v
 	}
-	if errors.Is(err, ErrBreak) {
-		err = nil
-	}
-	return res, err
+	return res, nil
 }
 
 func evalIf(n ast.IfNode, ev env.Environ[value.Value]) (value.Value, error) {
@@ -112,36 +363,92 @@ func evalIf(n ast.IfNode, ev env.Environ[value.Value]) (value.Value, error) {
 }
 
 func evalSwitch(n ast.SwitchNode, ev env.Environ[value.Value]) (value.Value, error) {
-	return nil, nil
+	v, err := eval(n.Cdt, ev)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		scope = env.EnclosedEnv(ev)
+		match = -1
+		def   = -1
+		res   value.Value
+	)
+	for i := range n.Cases {
+		c, ok := n.Cases[i].(ast.CaseNode)
+		if !ok {
+			return nil, ErrEval
+		}
+		if c.Predicate == nil {
+			def = i
+			continue
+		}
+		p, err := eval(c.Predicate, scope)
+		if err != nil {
+			return nil, err
+		}
+		if ok, err := strictEqual(v, p); err != nil {
+			return nil, err
+		} else if ok.True() {
+			match = i
+			break
+		}
+	}
+	if match < 0 {
+		match = def
+	}
+	if match < 0 {
+		return nil, nil
+	}
+	for _, c := range n.Cases[match:] {
+		c, ok := c.(ast.CaseNode)
+		if !ok {
+			return nil, ErrEval
+		}
+		res, err = eval(c.Body, scope)
+		if err != nil {
+			var ctrl controlError
+			if errors.Is(err, ErrBreak) && !errors.As(err, &ctrl) {
+				err = nil
+			}
+			break
+		}
+	}
+	return res, err
 }
 
 func evalThrow(n ast.ThrowNode, ev env.Environ[value.Value]) (value.Value, error) {
 	v, err := eval(n.Node, ev)
-	if err == nil {
-		err = ErrThrow
+	if err != nil {
+		return nil, err
+	}
+	return nil, ThrowError{
+		Value: v,
 	}
-	return v, err
 }
 
-func evalCatch(n ast.CatchNode, ev env.Environ[value.Value]) (value.Value, error) {
-	return eval(n.Body, ev)
+func evalCatch(n ast.CatchNode, exc error, ev env.Environ[value.Value]) (value.Value, error) {
+	scope := env.EnclosedEnv(ev)
+	if n.Ident != nil {
+		if err := bindValue(n.Ident, thrownValue(exc, ev), scope, false); err != nil {
+			return nil, err
+		}
+	}
+	return eval(n.Body, scope)
 }
 
 func evalTry(n ast.TryNode, ev env.Environ[value.Value]) (value.Value, error) {
 	v, err := eval(n.Try, env.EnclosedEnv(ev))
-	if err == nil {
-		return v, err
-	}
-	if n.Catch != nil {
-		if _, ok := n.Catch.(ast.CatchNode); ok {
-			// define error in env
+	if err != nil && isCatchable(err) && n.Catch != nil {
+		c, ok := n.Catch.(ast.CatchNode)
+		if !ok {
+			return nil, ErrEval
 		}
-		v, err = eval(n.Catch, env.EnclosedEnv(ev))
+		v, err = evalCatch(c, err, ev)
 	}
 	if n.Finally != nil {
-		_, err1 := eval(n.Finally, env.EnclosedEnv(ev))
-		if err == nil {
-			err = err1
+		fv, ferr := eval(n.Finally, env.EnclosedEnv(ev))
+		if ferr != nil {
+			return fv, ferr
 		}
 	}
 	return v, err
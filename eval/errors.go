@@ -0,0 +1,185 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/midbel/enjoy/builtins"
+	"github.com/midbel/enjoy/env"
+	"github.com/midbel/enjoy/token"
+	"github.com/midbel/enjoy/value"
+)
+
+type frame struct {
+	env.Environ[value.Value]
+	ident string
+	pos   token.Position
+	prev  *frame
+}
+
+func enterFrame(ident string, ev, caller env.Environ[value.Value]) *frame {
+	return &frame{
+		Environ: ev,
+		ident:   ident,
+		prev:    currentFrame(caller),
+	}
+}
+
+func currentFrame(ev env.Environ[value.Value]) *frame {
+	for ev != nil {
+		switch e := ev.(type) {
+		case *frame:
+			return e
+		case interface {
+			Parent() env.Environ[value.Value]
+		}:
+			ev = e.Parent()
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (f *frame) String() string {
+	var pos string
+	if f.pos.Line > 0 {
+		pos = fmt.Sprintf("%d:%d", f.pos.Line, f.pos.Column)
+	}
+	if f.prev == nil && f.ident == "" {
+		return pos
+	}
+	ident := f.ident
+	if ident == "" {
+		ident = "<anonymous>"
+	}
+	if pos == "" {
+		return ident
+	}
+	return fmt.Sprintf("%s (%s)", ident, pos)
+}
+
+func stackTrace(v value.Value, ev env.Environ[value.Value]) string {
+	var str strings.Builder
+	str.WriteString(describeError(v))
+	for f := currentFrame(ev); f != nil; f = f.prev {
+		at := f.String()
+		if at == "" {
+			continue
+		}
+		str.WriteString("\n    at ")
+		str.WriteString(at)
+	}
+	return str.String()
+}
+
+func attachStack(v value.Value, ev env.Environ[value.Value]) {
+	if !builtins.IsError(v) {
+		return
+	}
+	obj := v.(*value.Object)
+	if obj.Has("stack") {
+		return
+	}
+	obj.Define("stack", value.Descriptor{
+		Value:        value.CreateString(stackTrace(v, ev)),
+		Writable:     true,
+		Configurable: true,
+	})
+}
+
+func describeError(v value.Value) string {
+	if !builtins.IsError(v) {
+		return v.String()
+	}
+	name, _ := value.Get(v, "name")
+	msg, _ := value.Get(v, "message")
+	if msg.String() == "" {
+		return name.String()
+	}
+	return fmt.Sprintf("%s: %s", name, msg)
+}
+
+type ThrowError struct {
+	Value value.Value
+	Err   error
+}
+
+func (e ThrowError) Error() string {
+	return fmt.Sprintf("uncaught exception: %s", describeError(e.Value))
+}
+
+func (e ThrowError) Unwrap() []error {
+	list := []error{ErrThrow}
+	if e.Err != nil {
+		list = append(list, e.Err)
+	}
+	return list
+}
+
+func isCatchable(err error) bool {
+	switch {
+	case errors.Is(err, ErrBreak):
+	case errors.Is(err, ErrContinue):
+	case errors.Is(err, ErrReturn):
+	default:
+		return true
+	}
+	return false
+}
+
+func throwError(err error, ev env.Environ[value.Value]) error {
+	if err == nil || !isCatchable(err) {
+		return err
+	}
+	var e ThrowError
+	if errors.As(err, &e) {
+		return err
+	}
+	exc := builtins.CreateError(ev, errorName(err), err.Error())
+	attachStack(exc, ev)
+	return ThrowError{
+		Value: exc,
+		Err:   err,
+	}
+}
+
+func thrownValue(err error, ev env.Environ[value.Value]) value.Value {
+	var e ThrowError
+	if errors.As(throwError(err, ev), &e) {
+		return e.Value
+	}
+	return value.Undefined()
+}
+
+func errorName(err error) string {
+	switch {
+	case errors.Is(err, env.ErrNotDefined):
+		return "ReferenceError"
+	case errors.Is(err, env.ErrUninitialized):
+		return "ReferenceError"
+	case errors.Is(err, env.ErrDefined):
+		return "SyntaxError"
+	case errors.Is(err, ErrExport):
+		return "SyntaxError"
+	case errors.Is(err, value.ErrPattern):
+		return "SyntaxError"
+	case errors.Is(err, env.ErrAssign):
+		return "TypeError"
+	case errors.Is(err, value.ErrOperation):
+		return "TypeError"
+	case errors.Is(err, ErrDestruct):
+		return "TypeError"
+	case errors.Is(err, value.ErrIncompatible):
+		return "TypeError"
+	case errors.Is(err, value.ErrArgument):
+		return "TypeError"
+	case errors.Is(err, value.ErrIndex):
+		return "RangeError"
+	case errors.Is(err, value.ErrZero):
+		return "RangeError"
+	default:
+		return "Error"
+	}
+}
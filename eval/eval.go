@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/midbel/enjoy/ast"
@@ -20,20 +22,38 @@ var (
 	ErrReturn   = errors.New("return")
 	ErrThrow    = errors.New("throw")
 	ErrEval     = errors.New("node can not be evalualed in current context")
+	ErrModule   = errors.New("module can not be loaded")
+	ErrExport   = errors.New("export not defined")
+	ErrDestruct = errors.New("value can not be destructured")
+
+	errChain = errors.New("optional chain short-circuited")
 )
 
 func Default() env.Environ[value.Value] {
+	loop := NewLoop()
 	top := env.EmptyEnv[value.Value]()
+	top.Define(loopIdent, loop, true)
 	top.Define("console", builtins.Console(), true)
 	top.Define("Math", builtins.Math(), true)
 	top.Define("Object", builtins.Object(), true)
+	top.Define("Symbol", builtins.Symbol(), true)
 	top.Define("JSON", builtins.Json(), true)
+	top.Define("RegExp", builtins.RegExp(), true)
 	top.Define("XML", builtins.Xml(), true)
 
+	builtins.DefineErrors(top)
+	top.Define("Promise", promiseCtor(loop), true)
+	top.Define("setTimeout", setTimer(loop, false), true)
+	top.Define("setInterval", setTimer(loop, true), true)
+	top.Define("clearTimeout", clearTimer(loop), true)
+	top.Define("clearInterval", clearTimer(loop), true)
+	top.Define("queueMicrotask", queueMicrotask(loop), true)
+
 	top.Define("parseInt", builtins.ParseInt(), true)
 	top.Define("parseFloat", builtins.ParseFloat(), true)
 	top.Define("print", builtins.Print(), true)
 
+	loop.top = top
 	return env.Immutable(top)
 }
 
@@ -55,16 +75,50 @@ func (e evaluableNode) Eval(ev env.Environ[value.Value]) (value.Value, error) {
 	return v, err
 }
 
+func (e evaluableNode) Run(fn value.Func, this value.Value, args []value.Value) (value.Value, error) {
+	return execUserFunc(fn, this, args, fn.Env)
+}
+
 func EvalDefault(r io.Reader) (value.Value, error) {
-	return Eval(r, env.EnclosedEnv(Default()))
+	top := Default()
+	defer LoopOf(top).Close()
+	return Eval(r, env.EnclosedEnv(top))
 }
 
 func Eval(r io.Reader, ev env.Environ[value.Value]) (value.Value, error) {
+	return EvalWith(r, ev, OSResolver())
+}
+
+func EvalWith(r io.Reader, ev env.Environ[value.Value], res Resolver) (value.Value, error) {
 	n, err := parser.Parse(r)
 	if err != nil {
 		return nil, err
 	}
-	return eval(n, ev)
+	root := enterFrame("", defaultContext(ev, res), nil)
+	if err := hoistVars(n, root); err != nil {
+		return nil, err
+	}
+	v, err := evalProgram(n, root)
+	if err == nil {
+		err = runLoop(ev)
+	}
+	return v, throwError(err, root)
+}
+
+func EvalFile(file string, ev env.Environ[value.Value]) (value.Value, error) {
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	return EvalModule(file, ev, OSResolver())
+}
+
+func EvalModule(name string, ev env.Environ[value.Value], res Resolver) (value.Value, error) {
+	_, v, err := defaultContext(ev, res).evaluate(name)
+	if err == nil {
+		err = runLoop(ev)
+	}
+	return v, err
 }
 
 func eval(node ast.Node, ev env.Environ[value.Value]) (value.Value, error) {
@@ -79,6 +133,8 @@ func eval(node ast.Node, ev env.Environ[value.Value]) (value.Value, error) {
 		return value.CreateFloat(n.Literal), nil
 	case ast.ValueNode[bool]:
 		return value.CreateBool(n.Literal), nil
+	case ast.RegexNode:
+		return value.CreateRegExp(n.Pattern, n.Flags)
 	case ast.TemplateNode:
 		return evalTemplate(n, ev)
 	case ast.VarNode:
@@ -91,10 +147,22 @@ func eval(node ast.Node, ev env.Environ[value.Value]) (value.Value, error) {
 		return evalSpread(n, ev)
 	case ast.TypeofNode:
 		return evalTypeOf(n, ev)
+	case ast.NewNode:
+		return evalNew(n, ev)
+	case ast.ClassNode:
+		return evalClass(n, ev)
+	case ast.ThisNode:
+		return evalThis(n, ev)
+	case ast.SuperNode:
+		return evalSuper(n, ev)
+	case ast.InstanceOfNode:
+		return evalInstanceOf(n, ev)
+	case ast.InNode:
+		return evalIn(n, ev)
 	case ast.IndexNode:
-		return evalIndex(n, ev)
+		return endChain(evalIndex(n, ev))
 	case ast.MemberNode:
-		return evalMember(n, ev)
+		return endChain(evalMember(n, ev))
 	case ast.SeqNode:
 		return evalSeq(n, ev)
 	case ast.BlockNode:
@@ -102,65 +170,69 @@ func eval(node ast.Node, ev env.Environ[value.Value]) (value.Value, error) {
 	case ast.LabelNode:
 		return evalLabel(n, ev)
 	case ast.BreakNode:
-		return nil, ErrBreak
+		return nil, breakLabel(n.Label)
 	case ast.ContinueNode:
-		return nil, ErrContinue
+		return nil, continueLabel(n.Label)
 	case ast.LetNode:
 		return evalLet(n, ev)
 	case ast.ConstNode:
 		return evalConst(n, ev)
+	case ast.VarDeclNode:
+		return evalVarDecl(n, ev)
 	case ast.AssignNode:
 		return evalAssign(n, ev)
 	case ast.UnaryNode:
 		return evalUnary(n, ev)
+	case ast.PostfixNode:
+		return evalPostfix(n, ev)
 	case ast.BinaryNode:
 		return evalBinary(n, ev)
 	case ast.TryNode:
 		return evalTry(n, ev)
-	case ast.CatchNode:
 	case ast.ThrowNode:
+		return evalThrow(n, ev)
 	case ast.IfNode:
 		return evalIf(n, ev)
 	case ast.SwitchNode:
 		return evalSwitch(n, ev)
 	case ast.WhileNode:
-		return evalWhile(n, ev)
+		return evalWhile(n, "", ev)
 	case ast.DoNode:
-		return evalDo(n, ev)
+		return evalDo(n, "", ev)
 	case ast.ForNode:
-		return evalFor(n, ev)
+		return evalFor(n, "", ev)
 	case ast.LoopNode:
-		return evalLoop(n, ev)
+		return evalLoop(n, "", ev)
 	case ast.FuncNode:
 		return evalFunc(n, ev)
 	case ast.ArrowNode:
 		return evalArrow(n, ev)
 	case ast.CallNode:
-		return evalCall(n, ev)
+		return endChain(evalCall(n, ev))
 	case evaluableNode:
 		return eval(n.Node, ev)
 	case ast.ReturnNode:
 		return evalReturn(n, ev)
-	case ast.ImportNode:
-		return evalImport(n, ev)
+	case ast.YieldNode:
+		return evalYield(n, ev)
+	case ast.AwaitNode:
+		return evalAwait(n, ev)
+	case ast.ImportNode, ast.ExportFromNode:
+		// bound by linkModule before the module body runs
+		return value.Undefined(), nil
 	case ast.ExportNode:
 		return evalExport(n, ev)
-	case ast.ExportFromNode:
-		return evalExportFrom(n, ev)
 	default:
 		return nil, fmt.Errorf("node type %T not recognized", node)
 	}
-	return nil, nil
 }
 
 func evalConst(n ast.ConstNode, ev env.Environ[value.Value]) (value.Value, error) {
 	switch x := n.Ident.(type) {
 	case ast.VarNode:
 		return setVar(x, n.Expr, ev, true)
-	case ast.BindingArrayNode:
-		return evalBindArray(x, n.Expr, ev, true)
-	case ast.BindingObjectNode:
-		return evalBindObject(x, n.Expr, ev, true)
+	case ast.BindingArrayNode, ast.BindingObjectNode:
+		return evalBinding(x, n.Expr, ev, true)
 	default:
 		return nil, ErrEval
 	}
@@ -170,135 +242,219 @@ func evalLet(n ast.LetNode, ev env.Environ[value.Value]) (value.Value, error) {
 	switch x := n.Ident.(type) {
 	case ast.VarNode:
 		return setVar(x, n.Expr, ev, false)
-	case ast.BindingArrayNode:
-		return evalBindArray(x, n.Expr, ev, false)
-	case ast.BindingObjectNode:
-		return evalBindObject(x, n.Expr, ev, false)
+	case ast.BindingArrayNode, ast.BindingObjectNode:
+		return evalBinding(x, n.Expr, ev, false)
 	default:
 		return nil, ErrEval
 	}
 }
 
-func evalBindObject(o ast.BindingObjectNode, n ast.Node, ev env.Environ[value.Value], ro bool) (value.Value, error) {
-	if ro && n == nil {
+func evalBinding(b ast.Node, n ast.Node, ev env.Environ[value.Value], ro bool) (value.Value, error) {
+	if n == nil {
 		return nil, ErrEval
 	}
-	var (
-		res = value.Undefined()
-		err error
-	)
-	if n != nil {
-		res, err = eval(n, ev)
-		if err != nil {
-			return nil, err
-		}
-	}
-	if value.IsUndefined(res) || value.IsNull(res) {
-		return nil, ErrEval
+	res, err := eval(n, ev)
+	if err != nil {
+		return nil, err
 	}
-	return res, bindObject(o, res, ev, ro)
+	return res, bindValue(b, res, ev, ro)
 }
 
-func bindObject(o ast.BindingObjectNode, v value.Value, ev env.Environ[value.Value], ro bool) error {
-	obj, ok := v.(*value.Object)
-	if !ok {
-		return nil
-	}
-	var err error
-	for k, n := range o.List {
-		v, _ := obj.Get(k)
-		if v == nil {
-			v = value.Undefined()
-		}
-		a, ok := n.(ast.AssignNode)
+type bindFunc func(ast.Node, value.Value) error
+
+func bindValue(n ast.Node, v value.Value, ev env.Environ[value.Value], ro bool) error {
+	define := func(n ast.Node, v value.Value) error {
+		id, ok := n.(ast.VarNode)
 		if !ok {
 			return ErrEval
 		}
-		switch i := a.Ident.(type) {
-		case ast.VarNode:
-			if value.IsUndefined(v) && a.Expr != nil {
-				v, err = eval(a.Expr, ev)
-				if err != nil {
-					break
+		return ev.Define(id.Ident, v, ro)
+	}
+	return bindPattern(n, v, ev, define)
+}
+
+func bindPattern(n ast.Node, v value.Value, ev env.Environ[value.Value], bind bindFunc) error {
+	switch n := n.(type) {
+	case ast.DiscardNode:
+		return nil
+	case ast.AssignNode:
+		if value.IsUndefined(v) && n.Expr != nil {
+			var err error
+			if v, err = eval(n.Expr, ev); err != nil {
+				return err
+			}
+		}
+		return bindPattern(n.Ident, v, ev, bind)
+	case ast.BindingArrayNode:
+		return bindArray(n, v, ev, bind)
+	case ast.BindingObjectNode:
+		return bindObject(n, v, ev, bind)
+	default:
+		return bind(n, v)
+	}
+}
+
+func bindObject(o ast.BindingObjectNode, v value.Value, ev env.Environ[value.Value], bind bindFunc) error {
+	if value.IsUndefined(v) || value.IsNull(v) {
+		return fmt.Errorf("cannot destructure %s: %w", v, ErrDestruct)
+	}
+	var seen []any
+	for _, n := range o.List {
+		switch n := n.(type) {
+		case ast.PropNode:
+			key, err := eval(n.Key, ev)
+			if err != nil {
+				return err
+			}
+			prop := value.Undefined()
+			if sym, ok := key.(*value.Symbol); ok {
+				seen = append(seen, sym)
+				prop = getSymbol(v, sym)
+			} else if g, ok := v.(value.Getter); ok {
+				seen = append(seen, key.String())
+				if prop, err = g.Get(key.String()); err != nil {
+					return err
 				}
+			} else {
+				seen = append(seen, key.String())
+			}
+			if err := bindPattern(n.Value, prop, ev, bind); err != nil {
+				return err
+			}
+		case ast.SpreadNode:
+			rest, err := restObject(v, seen)
+			if err != nil {
+				return err
+			}
+			if err := bind(n.Node, rest); err != nil {
+				return err
 			}
-			err = ev.Define(i.Ident, v, ro)
-		case ast.BindingObjectNode:
-			err = bindObject(i, v, ev, ro)
 		default:
 			return ErrEval
 		}
-		if err != nil {
-			break
-		}
 	}
-	return err
+	return nil
 }
 
-func evalBindArray(a ast.BindingArrayNode, n ast.Node, ev env.Environ[value.Value], ro bool) (value.Value, error) {
-	if ro && n == nil {
-		return nil, ErrEval
-	}
+func restObject(v value.Value, seen []any) (value.Value, error) {
 	var (
-		res = value.Undefined()
-		err error
+		obj  = value.CreateObject(nil).(*value.Object)
+		keys []string
 	)
-	if n != nil {
-		res, err = eval(n, ev)
+	switch v := v.(type) {
+	case *value.Object:
+		for _, k := range v.Names() {
+			if d, _ := v.GetOwn(k); d.Enumerable {
+				keys = append(keys, k)
+			}
+		}
+	case *value.Array, value.Str:
+		list, err := v.(value.Spreadable).Spread()
 		if err != nil {
 			return nil, err
 		}
+		for i := range list {
+			keys = append(keys, strconv.Itoa(i))
+		}
 	}
-	if value.IsUndefined(res) || value.IsNull(res) {
-		return nil, ErrEval
-	}
-	return res, bindArray(a, res, ev, ro)
-}
-
-func bindArray(a ast.BindingArrayNode, v value.Value, ev env.Environ[value.Value], ro bool) error {
-	arr, ok := v.(*value.Array)
-	if !ok {
-		return ErrEval
+	for _, k := range keys {
+		if slices.Contains(seen, any(k)) {
+			continue
+		}
+		p, err := value.Get(v, k)
+		if err != nil {
+			return nil, err
+		}
+		if err := obj.Set(k, p); err != nil {
+			return nil, err
+		}
 	}
-	var (
-		nodes = slices.Clone(a.List)
-		err   error
-	)
-	for i := 0; i < len(nodes); i++ {
-		val, _ := arr.At(value.CreateFloat(float64(i)))
-		switch n := nodes[i].(type) {
-		case ast.DiscardNode:
-		case ast.VarNode:
-			err = ev.Define(n.Ident, val, ro)
-		case ast.AssignNode:
-			id, ok := n.Ident.(ast.VarNode)
-			if !ok {
-				return ErrEval
+	if src, ok := v.(*value.Object); ok {
+		for _, k := range src.Symbols() {
+			if d, _ := src.GetOwnSymbol(k); !d.Enumerable || slices.Contains(seen, any(k)) {
+				continue
 			}
-			if value.IsUndefined(val) || value.IsNull(val) {
-				val, err = eval(n.Expr, ev)
+			p, err := src.GetSymbol(k)
+			if err != nil {
+				return nil, err
 			}
-			if err == nil {
-				err = ev.Define(id.Ident, val, ro)
+			if err := obj.SetSymbol(k, p); err != nil {
+				return nil, err
 			}
-		case ast.SpreadNode:
-			b, ok := n.Node.(ast.BindingArrayNode)
-			if !ok {
-				return ErrEval
+		}
+	}
+	return obj, nil
+}
+
+func bindArray(a ast.BindingArrayNode, v value.Value, ev env.Environ[value.Value], bind bindFunc) error {
+	it, err := getIterator(v, ev)
+	if err != nil {
+		return fmt.Errorf("%s is not iterable: %w", v, ErrDestruct)
+	}
+	var done bool
+	next := func() (value.Value, error) {
+		if done {
+			return value.Undefined(), nil
+		}
+		v, stop, err := it.Next()
+		if done = stop; done {
+			v = value.Undefined()
+		}
+		return v, err
+	}
+	for _, n := range a.List {
+		if s, ok := n.(ast.SpreadNode); ok {
+			var rest []value.Value
+			for {
+				v, err := next()
+				if err != nil {
+					return err
+				}
+				if done {
+					break
+				}
+				rest = append(rest, v)
 			}
-			tmp := slices.Clone(b.List)
-			nodes = append(nodes[:i], append(tmp, nodes[i+1:]...)...)
-			i--
-		case ast.BindingObjectNode:
-			err = bindObject(n, val, ev, ro)
-		default:
-			err = ErrEval
+			return bindPattern(s.Node, value.CreateArray(rest), ev, bind)
 		}
+		v, err := next()
 		if err != nil {
-			break
+			return err
+		}
+		if err := bindPattern(n, v, ev, bind); err != nil {
+			if !done {
+				closeIterator(it)
+			}
+			return err
+		}
+	}
+	if !done {
+		return closeIterator(it)
+	}
+	return nil
+}
+
+func bindingNames(n ast.Node) []string {
+	var list []string
+	switch n := n.(type) {
+	case ast.VarNode:
+		list = append(list, n.Ident)
+	case ast.AssignNode:
+		list = append(list, bindingNames(n.Ident)...)
+	case ast.SpreadNode:
+		list = append(list, bindingNames(n.Node)...)
+	case ast.PropNode:
+		list = append(list, bindingNames(n.Value)...)
+	case ast.BindingArrayNode:
+		for _, n := range n.List {
+			list = append(list, bindingNames(n)...)
+		}
+	case ast.BindingObjectNode:
+		for _, n := range n.List {
+			list = append(list, bindingNames(n)...)
 		}
 	}
-	return err
+	return list
 }
 
 func setVar(v ast.VarNode, n ast.Node, ev env.Environ[value.Value], ro bool) (value.Value, error) {
@@ -332,8 +488,40 @@ func evalSeq(n ast.SeqNode, ev env.Environ[value.Value]) (value.Value, error) {
 	return res, err
 }
 
+func evalChain(n ast.Node, ev env.Environ[value.Value]) (value.Value, error) {
+	switch n := n.(type) {
+	case ast.IndexNode:
+		return evalIndex(n, ev)
+	case ast.MemberNode:
+		return evalMember(n, ev)
+	case ast.CallNode:
+		return evalCall(n, ev)
+	default:
+		return eval(n, ev)
+	}
+}
+
+func evalLink(n ast.Node, optional bool, ev env.Environ[value.Value]) (value.Value, error) {
+	v, err := evalChain(n, ev)
+	if err == nil && optional && isNullish(v) {
+		err = errChain
+	}
+	return v, err
+}
+
+func endChain(v value.Value, err error) (value.Value, error) {
+	if errors.Is(err, errChain) {
+		return value.Undefined(), nil
+	}
+	return v, err
+}
+
+func isNullish(v value.Value) bool {
+	return value.IsNull(v) || value.IsUndefined(v)
+}
+
 func evalIndex(n ast.IndexNode, ev env.Environ[value.Value]) (value.Value, error) {
-	v, err := eval(n.Expr, ev)
+	v, err := evalLink(n.Expr, n.Optional, ev)
 	if err != nil {
 		return nil, err
 	}
@@ -341,11 +529,14 @@ func evalIndex(n ast.IndexNode, ev env.Environ[value.Value]) (value.Value, error
 	if err != nil {
 		return nil, err
 	}
+	if sym, ok := i.(*value.Symbol); ok {
+		return getSymbol(v, sym), nil
+	}
 	return value.At(v, i)
 }
 
 func evalMember(n ast.MemberNode, ev env.Environ[value.Value]) (value.Value, error) {
-	v, err := eval(n.Curr, ev)
+	v, err := evalLink(n.Curr, n.Optional, ev)
 	if err != nil {
 		return nil, err
 	}
@@ -353,6 +544,9 @@ func evalMember(n ast.MemberNode, ev env.Environ[value.Value]) (value.Value, err
 	if !ok {
 		return nil, ErrEval
 	}
+	if _, ok := n.Curr.(ast.SuperNode); ok {
+		return getSuper(v, id.Ident, ev)
+	}
 	return value.Get(v, id.Ident)
 }
 
@@ -369,9 +563,33 @@ func evalSpread(n ast.SpreadNode, ev env.Environ[value.Value]) (value.Value, err
 	if err != nil {
 		return nil, err
 	}
+	if _, ok := v.(value.Spreadable); !ok {
+		if v, err = collectValues(v, ev); err != nil {
+			return nil, err
+		}
+	}
 	return value.SpreadValue(v)
 }
 
+func collectValues(v value.Value, ev env.Environ[value.Value]) (value.Value, error) {
+	it, err := getIterator(v, ev)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not iterable: %w", v, err)
+	}
+	var list []value.Value
+	for {
+		v, done, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			break
+		}
+		list = append(list, v)
+	}
+	return value.CreateArray(list), nil
+}
+
 func evalArray(n ast.ArrayNode, ev env.Environ[value.Value]) (value.Value, error) {
 	var list []value.Value
 	for _, a := range n.List {
@@ -380,7 +598,11 @@ func evalArray(n ast.ArrayNode, ev env.Environ[value.Value]) (value.Value, error
 			return nil, err
 		}
 		if s, ok := v.(value.Spread); ok {
-			list = append(list, s.Spread()...)
+			vs, err := s.Spread()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, vs...)
 		} else {
 			list = append(list, v)
 		}
@@ -389,24 +611,158 @@ func evalArray(n ast.ArrayNode, ev env.Environ[value.Value]) (value.Value, error
 }
 
 func evalObject(n ast.ObjectNode, ev env.Environ[value.Value]) (value.Value, error) {
-	list := make(map[string]value.Value)
-	for k, a := range n.List {
-		v, err := eval(a, ev)
+	obj := value.CreateObject(nil).(*value.Object)
+	for _, n := range n.List {
+		var err error
+		switch n := n.(type) {
+		case ast.PropNode:
+			err = defineProp(obj, n, ev)
+		case ast.AccessorNode:
+			err = defineAccessor(obj, n, true, ev)
+		case ast.SpreadNode:
+			err = spreadObject(obj, n, ev)
+		default:
+			err = ErrEval
+		}
 		if err != nil {
 			return nil, err
 		}
-		list[k] = v
 	}
-	return value.CreateObject(list), nil
+	return obj, nil
+}
+
+func defineProp(obj *value.Object, n ast.PropNode, ev env.Environ[value.Value]) error {
+	key, err := eval(n.Key, ev)
+	if err != nil {
+		return err
+	}
+	var v value.Value
+	if m, ok := n.Value.(ast.MethodNode); ok {
+		fn, ok := m.Func.(ast.FuncNode)
+		if !ok {
+			return ErrEval
+		}
+		fn.Ident = keyName(key)
+		v, err = createFunc(fn, ev)
+	} else {
+		v, err = eval(n.Value, ev)
+	}
+	if err != nil {
+		return err
+	}
+	d := value.Descriptor{
+		Value:        v,
+		Writable:     true,
+		Enumerable:   true,
+		Configurable: true,
+	}
+	return defineKey(obj, key, d)
+}
+
+func defineAccessor(obj *value.Object, n ast.AccessorNode, enumerable bool, ev env.Environ[value.Value]) error {
+	key, err := propKey(n.Ident, n.Key, ev)
+	if err != nil {
+		return err
+	}
+	curr, ok := ownKey(obj, key)
+	if !ok || !curr.IsAccessor() {
+		curr = value.Descriptor{}
+	}
+	d, err := createAccessor(n, curr, ev)
+	if err != nil {
+		return err
+	}
+	d.Enumerable = enumerable
+	return defineKey(obj, key, d)
+}
+
+func spreadObject(obj *value.Object, n ast.SpreadNode, ev env.Environ[value.Value]) error {
+	v, err := eval(n.Node, ev)
+	if err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case *value.Object:
+		for _, k := range v.Names() {
+			if d, _ := v.GetOwn(k); !d.Enumerable {
+				continue
+			}
+			p, err := v.Get(k)
+			if err != nil {
+				return err
+			}
+			if err := obj.Set(k, p); err != nil {
+				return err
+			}
+		}
+		for _, k := range v.Symbols() {
+			if d, _ := v.GetOwnSymbol(k); !d.Enumerable {
+				continue
+			}
+			p, err := v.GetSymbol(k)
+			if err != nil {
+				return err
+			}
+			if err := obj.SetSymbol(k, p); err != nil {
+				return err
+			}
+		}
+	case *value.Array, value.Str:
+		list, err := v.(value.Spreadable).Spread()
+		if err != nil {
+			return err
+		}
+		for i, p := range list {
+			if err := obj.Set(strconv.Itoa(i), p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func createAccessor(n ast.AccessorNode, d value.Descriptor, ev env.Environ[value.Value]) (value.Descriptor, error) {
+	d.Value = nil
+	d.Writable = false
+	d.Configurable = true
+	if n.Get != nil {
+		fn, ok := n.Get.(ast.FuncNode)
+		if !ok {
+			return d, ErrEval
+		}
+		get, err := createFunc(fn, ev)
+		if err != nil {
+			return d, err
+		}
+		d.Get = get
+	}
+	if n.Set != nil {
+		fn, ok := n.Set.(ast.FuncNode)
+		if !ok {
+			return d, ErrEval
+		}
+		set, err := createFunc(fn, ev)
+		if err != nil {
+			return d, err
+		}
+		d.Set = set
+	}
+	return d, nil
 }
 
 func evalTemplate(n ast.TemplateNode, ev env.Environ[value.Value]) (value.Value, error) {
 	var list []string
 	for _, n := range n.Nodes {
 		v, err := eval(n, ev)
+		if err == nil {
+			v, err = toPrimitive(v, "string", ev)
+		}
 		if err != nil {
 			return nil, err
 		}
+		if _, ok := v.(*value.Symbol); ok {
+			return nil, fmt.Errorf("cannot convert %s to string: %w", v, value.ErrOperation)
+		}
 		list = append(list, v.String())
 	}
 	str := strings.Join(list, "")
@@ -0,0 +1,1044 @@
+package eval
+
+import (
+	"errors"
+	"math"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/midbel/enjoy/env"
+	"github.com/midbel/enjoy/value"
+)
+
+type evalCase struct {
+	Input string
+	Want  string
+}
+
+func TestFor(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `let s = 0; for (let i = 0; i < 5; i++) { s += i }; s`,
+			Want:  "10",
+		},
+		{
+			Input: `let s = 0; for (let i = 0; i < 5; i++) { if (i == 1) { continue }; if (i == 3) { break }; s += i }; s`,
+			Want:  "2",
+		},
+		{
+			Input: `let i; for (i = 10; i > 7; i--) {}; i`,
+			Want:  "7",
+		},
+		{
+			Input: `let i = 0; for (;;) { if (i == 3) { break }; i++ }; i`,
+			Want:  "3",
+		},
+		{
+			Input: `const fns = []; for (let i = 0; i < 3; i++) { fns.push(() => i) }; fns.map(f => f())`,
+			Want:  "[0, 1, 2]",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestForOf(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `const out = []; for (const x of [1, 2, 3]) { out.push(x * 10) }; out`,
+			Want:  "[10, 20, 30]",
+		},
+		{
+			Input: `const out = []; for (let [a, b] of [[1, 2], [3, 4]]) { out.push(a + b) }; out`,
+			Want:  "[3, 7]",
+		},
+		{
+			Input: `const out = []; for (const c of "héllo") { out.push(c) }; out`,
+			Want:  "[h, é, l, l, o]",
+		},
+		{
+			Input: `let x; for (x of [1, 2, 3, 4]) { if (x == 3) { break } }; x`,
+			Want:  "3",
+		},
+		{
+			Input: `function count(n) { let i = 0; return { next: () => { i++; return i > n ? {done: true} : {value: i, done: false} } } }; const out = []; for (const x of count(3)) { out.push(x) }; out`,
+			Want:  "[1, 2, 3]",
+		},
+		{
+			Input: `let closed = 0; function nat() { let i = 0; return { next: () => { i++; return {value: i, done: false} }, return: () => { closed++; return {done: true} } } }; const it = { [Symbol.iterator]: nat }; for (const x of it) { if (x > 1) { break } }; try { for (const x of it) { throw x } } catch (e) {}; const [a, b] = it; [a, b, closed]`,
+			Want:  "[1, 2, 3]",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestForIn(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `const obj = {b: 1, a: 2}; obj.z = 3; obj.c = 4; const out = []; for (const k in obj) { out.push(k) }; out`,
+			Want:  "[b, a, z, c]",
+		},
+		{
+			Input: `const obj = {x: 1}; obj.y = 2; Object.keys(obj)`,
+			Want:  "[x, y]",
+		},
+		{
+			Input: `const out = []; for (const i in [5, 6, 7]) { out.push(i) }; out`,
+			Want:  "[0, 1, 2]",
+		},
+		{
+			Input: `const out = []; for (const i in "abc") { if (i == "1") { continue }; out.push(i) }; out`,
+			Want:  "[0, 2]",
+		},
+		{
+			Input: `let n = 0; for (const k in null) { n++ }; n`,
+			Want:  "0",
+		},
+		{
+			Input: `function P() { this.own = 1 }; P.prototype.shared = 2; P.prototype.own = 3; class A { m() {} }; const a = new A(); a.x = 1; const out = []; for (const k in new P()) { out.push(k) }; for (const k in a) { out.push(k) }; out`,
+			Want:  "[own, shared, x]",
+		},
+		{
+			Input: `let r; try { Object.entries() } catch (e) { r = e.name }; r`,
+			Want:  "TypeError",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestSwitch(t *testing.T) {
+	check := `function check(x) {
+		const out = []
+		switch (x) {
+		default:
+			out.push("default")
+		case 1:
+			out.push("one")
+			break
+		case "2":
+			out.push("string")
+		case 2:
+			out.push("two")
+			break
+		}
+		return out
+	};`
+	tests := []evalCase{
+		{
+			Input: check + `check(1)`,
+			Want:  "[one]",
+		},
+		{
+			Input: check + `check(2)`,
+			Want:  "[two]",
+		},
+		{
+			Input: check + `check("2")`,
+			Want:  "[string, two]",
+		},
+		{
+			Input: check + `check(5)`,
+			Want:  "[default, one]",
+		},
+		{
+			Input: `const y = 3; let r = "none"; switch (3) { case y: r = "y"; break }; r`,
+			Want:  "y",
+		},
+		{
+			Input: `let r = 0; for (let i = 0; i < 4; i++) { switch (i) { case 1: continue; default: r += i } }; r`,
+			Want:  "5",
+		},
+		{
+			Input: `function f() {}; const p = Promise.resolve(1); const g = (function*() {})(); const b = f.bind(null); const r = /a/; [p === p, g === g, b === b, r === r, b === f.bind(null), r === /a/]`,
+			Want:  "[true, true, true, true, false, false]",
+		},
+		{
+			Input: `const r = /a/; let out = "none"; switch (r) { case /a/: out = "other"; break; case r: out = "same" }; out`,
+			Want:  "same",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestLabel(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `const out = []; outer: for (let i = 0; i < 3; i++) { for (let j = 0; j < 3; j++) { if (j == 1) { continue outer }; if (i == 2) { break outer }; out.push(i * 10 + j) } }; out`,
+			Want:  "[0, 10]",
+		},
+		{
+			Input: `let k = 0; loop: while (true) { k++; do { if (k > 3) { break loop }; continue loop } while (false) }; k`,
+			Want:  "4",
+		},
+		{
+			Input: `let r = 0; found: for (const row of [[1, 2], [3, 4]]) { for (const x of row) { if (x == 3) { r = x; break found } } }; r`,
+			Want:  "3",
+		},
+		{
+			Input: `let r = "ok"; sw: switch (1) { case 1: for (const x of [1]) { break sw }; r = "ko" }; r`,
+			Want:  "ok",
+		},
+		{
+			Input: `function f() { while (true) { return 42 } }; f()`,
+			Want:  "42",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestTry(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `let r; try { throw "boom" } catch (e) { r = e }; r`,
+			Want:  "boom",
+		},
+		{
+			Input: `let r = []; try { throw {code: 42, msg: "bad"} } catch ({code, msg}) { r.push(code, msg) } finally { r.push("done") }; r`,
+			Want:  "[42, bad, done]",
+		},
+		{
+			Input: `let r; try { notDefined + 1 } catch (e) { r = e.name }; r`,
+			Want:  "ReferenceError",
+		},
+		{
+			Input: `const r = []; function f() { try { return "try" } finally { r.push("finally") } }; r.push(f()); r`,
+			Want:  "[finally, try]",
+		},
+		{
+			Input: `function f() { try { return "try" } finally { return "finally" } }; f()`,
+			Want:  "finally",
+		},
+		{
+			Input: `const r = []; for (const x of [1, 2, 3]) { try { if (x == 1) { continue }; break } finally { r.push(x) } }; r`,
+			Want:  "[1, 2]",
+		},
+		{
+			Input: `const r = []; try { try { throw 1 } finally { r.push("inner") } } catch { r.push("outer") }; r`,
+			Want:  "[inner, outer]",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestError(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `const e = Error("boom"); e.name + ": " + e.message`,
+			Want:  "Error: boom",
+		},
+		{
+			Input: "const e = TypeError(\"bad\"); const f = Error(); f.name = \"\"; f.message = \"msg\"; [e.toString(), RangeError().toString(), f.toString(), `${e}`]",
+			Want:  "[TypeError: bad, RangeError, msg, TypeError: bad]",
+		},
+		{
+			Input: `const e = RangeError("out", {cause: 42}); [e.name, e.cause, e instanceof RangeError, e instanceof Error, e instanceof TypeError]`,
+			Want:  "[RangeError, 42, true, true, false]",
+		},
+		{
+			Input: `let r; try { undefinedVariable } catch (e) { r = [e.name, e instanceof ReferenceError] }; r`,
+			Want:  "[ReferenceError, true]",
+		},
+		{
+			Input: `const o = {}; let res; try { o.x.y } catch (e) { res = e.name }; res`,
+			Want:  "TypeError",
+		},
+		{
+			Input: `let r; try { const x = 1; x = 2 } catch (e) { r = e instanceof TypeError }; r`,
+			Want:  "true",
+		},
+		{
+			Input: `function fail() { throw TypeError("bad") }; let r; try { fail() } catch (e) { r = e.stack.includes("at fail") }; r`,
+			Want:  "true",
+		},
+		{
+			Input: `const e = Error("x"); ["message" in e, "stack" in e, Object.keys(e).length]`,
+			Want:  "[true, true, 0]",
+		},
+		{
+			Input: `function named() { try { null.x } catch (e) { return e.stack } }; const later = new Promise(r => setTimeout(() => r(named()))); Promise.all([Promise.resolve().then(named), later]).then(list => list.map(s => [s.includes("at named"), s.endsWith("at ")]))`,
+			Want:  "Promise { [[true, false], [true, false]] }",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestErrorRuntime(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			EvalDefault(strings.NewReader(`TypeError.prototype.leak = 1; new TypeError("x")`))
+		}()
+	}
+	wg.Wait()
+	v, err := EvalDefault(strings.NewReader(`let e; try { null.x } catch (err) { e = err }; [new TypeError("x").leak, e instanceof TypeError]`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, want := v.String(), "[undefined, true]"; got != want {
+		t.Errorf("results mismatched! want %s, got %s", want, got)
+	}
+}
+
+func TestClass(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `class A { constructor(x) { this.x = x }; get() { return this.x } }; new A(42).get()`,
+			Want:  "42",
+		},
+		{
+			Input: `class A { x = 1; static y = 2 }; const a = new A(); [a.x, A.y, a.y]`,
+			Want:  "[1, 2, undefined]",
+		},
+		{
+			Input: `class A { hello() { return "A" } }; class B extends A { hello() { return super.hello() + "B" } }; new B().hello()`,
+			Want:  "AB",
+		},
+		{
+			Input: `class A { constructor(x) { this.x = x } }; class B extends A { constructor() { super(1); this.y = this.x + 1 } }; const b = new B(); [b.x, b.y]`,
+			Want:  "[1, 2]",
+		},
+		{
+			Input: `class A {}; class B extends A {}; const b = new B(); [b instanceof B, b instanceof A, new A() instanceof B]`,
+			Want:  "[true, true, false]",
+		},
+		{
+			Input: `class A { static make() { return new this() } }; A.make() instanceof A`,
+			Want:  "true",
+		},
+		{
+			Input: `class E extends Error { constructor(m) { super(m); this.name = "E" } }; const e = new E("x"); [e instanceof E, e instanceof Error, e.message, e.name]`,
+			Want:  "[true, true, x, E]",
+		},
+		{
+			Input: `function P(x) { this.x = x }; P.prototype.double = function() { return this.x * 2 }; new P(2).double()`,
+			Want:  "4",
+		},
+		{
+			Input: `class A {}; let r; try { A() } catch (e) { r = e instanceof TypeError }; r`,
+			Want:  "true",
+		},
+		{
+			Input: `class A { static s() { return "s:" + this.name }; static n = 1 }; class B extends A { static t() { return super.s() } }; [B.s(), B.n, B.t()]`,
+			Want:  "[s:B, 1, s:B]",
+		},
+		{
+			Input: `class A { constructor() { this.x = 2 }; get d() { return this.x * 2 }; set d(v) { this.x = v } }; class B extends A { get d() { return super.d + 1 }; set d(v) { super.d = v * 10 } }; const b = new B(); const r = [b.d]; b.d = 1; [r[0], b.x, b.d]`,
+			Want:  "[5, 10, 21]",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestThis(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `const o = { n: 1, get: function() { return this.n } }; o.get()`,
+			Want:  "1",
+		},
+		{
+			Input: `const o = { n: 1, get: function() { const f = () => this.n; return f() } }; o.get()`,
+			Want:  "1",
+		},
+		{
+			Input: `function f(a, b) { return this.n + a + b }; [f.call({n: 1}, 2, 3), f.apply({n: 10}, [20, 30])]`,
+			Want:  "[6, 60]",
+		},
+		{
+			Input: `function f(a, b) { return this.n + a + b }; const g = f.bind({n: 1}, 2); [g(3), g.length]`,
+			Want:  "[6, 1]",
+		},
+		{
+			Input: `function f(a) {}; [Math.max.bind(null).length, f.bind(null, 1, 2).length]`,
+			Want:  "[0, 0]",
+		},
+		{
+			Input: `function f() { return this }; f()`,
+			Want:  "undefined",
+		},
+		{
+			Input: `const o = {}; let r; try { o.missing() } catch (e) { r = e instanceof TypeError }; r`,
+			Want:  "true",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestProperty(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `const o = { a: 1, get double() { return this.a * 2 }, set double(v) { this.a = v / 2 } }; o.double = 10; [o.a, o.double]`,
+			Want:  "[5, 10]",
+		},
+		{
+			Input: `class A { constructor() { this.v = 1 }; get value() { return this.v }; static get kind() { return "A" } }; [new A().value, A.kind]`,
+			Want:  "[1, A]",
+		},
+		{
+			Input: `const o = {}; Object.defineProperty(o, "x", { value: 1 }); let r; try { o.x = 2 } catch (e) { r = e.name }; [o.x, r, Object.keys(o).length]`,
+			Want:  "[1, TypeError, 0]",
+		},
+		{
+			Input: `const o = {}; Object.defineProperty(o, "x", { value: 1 }); let r; try { Object.defineProperty(o, "x", { value: 2 }) } catch (e) { r = e.name }; r`,
+			Want:  "TypeError",
+		},
+		{
+			Input: `const o = { a: 1 }; Object.getOwnPropertyDescriptor(o, "a")`,
+			Want:  "{value:1, writable:true, enumerable:true, configurable:true}",
+		},
+		{
+			Input: `const o = {}; Object.defineProperties(o, { a: { value: 1, enumerable: true }, b: { get: function() { return this.a + 1 } } }); [o.b, Object.getOwnPropertyNames(o)]`,
+			Want:  "[2, [a, b]]",
+		},
+		{
+			Input: `const o = Object.freeze({ a: 1 }); let r; try { o.a = 2 } catch (e) { r = e.name }; [o.a, r, Object.getOwnPropertyDescriptor(o, "a").writable]`,
+			Want:  "[1, TypeError, false]",
+		},
+		{
+			Input: `const out = []; for (const f of [Object.getOwnPropertyDescriptors, Object.getOwnPropertyNames]) { try { f() } catch (e) { out.push(e.name) } }; out`,
+			Want:  "[TypeError, TypeError]",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestGenerator(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `function* gen() { yield 1; yield 2; return 3 }; const g = gen(); [g.next().value, g.next().value, g.next().value, g.next().done]`,
+			Want:  "[1, 2, 3, true]",
+		},
+		{
+			Input: `function* gen() { const x = yield 1; yield x * 2 }; const g = gen(); g.next(); g.next(21).value`,
+			Want:  "42",
+		},
+		{
+			Input: `function* inner() { yield 1; return 2 }; function* outer() { const r = yield* inner(); yield r; yield* [3, 4] }; [...outer()]`,
+			Want:  "[1, 2, 3, 4]",
+		},
+		{
+			Input: `function* nat() { let i = 0; while (true) { yield i++ } }; const res = []; for (const n of nat()) { if (n > 3) { break }; res.push(n) }; res`,
+			Want:  "[0, 1, 2, 3]",
+		},
+		{
+			Input: `let done = false; function* gen() { try { yield 1; yield 2 } finally { done = true } }; const g = gen(); g.next(); [g.return(5).value, done, g.next().done]`,
+			Want:  "[5, true, true]",
+		},
+		{
+			Input: `function* gen() { try { yield 1 } catch (e) { yield e } }; const g = gen(); g.next(); g.throw("err").value`,
+			Want:  "err",
+		},
+		{
+			Input: `class Bag { constructor() { this.items = [1, 2] }; *each() { yield* this.items } }; [...new Bag().each()]`,
+			Want:  "[1, 2]",
+		},
+		{
+			Input: `const arr = ["a", "b"]; [[...arr.keys()], [...arr.values()], [...arr.entries()]]`,
+			Want:  "[[0, 1], [a, b], [[0, a], [1, b]]]",
+		},
+		{
+			Input: `function* g() { yield 1; throw new Error("boom") }; const out = []; try { [...g()] } catch (e) { out.push(e.message) }; try { Math.max(...g()) } catch (e) { out.push(e.message) }; try { const {...r} = [...g()] } catch (e) { out.push(e.message) }; out`,
+			Want:  "[boom, boom, boom]",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestGeneratorRelease(t *testing.T) {
+	const script = `
+function* gen() { yield 1; yield 2 }
+const g = gen(); g.next()
+async function wait() { await new Promise(() => {}) }
+wait()
+`
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		if _, err := EvalDefault(strings.NewReader(script)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	var after int
+	for i := 0; i < 100; i++ {
+		if after = runtime.NumGoroutine(); after <= before {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("goroutines leaked: %d before, %d after", before, after)
+}
+
+func TestPromise(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `new Promise((resolve) => resolve(1)).then(v => v + 1).then(v => v * 2)`,
+			Want:  "Promise { 4 }",
+		},
+		{
+			Input: `const order = []; Promise.resolve().then(() => order.push("job")); order.push("sync"); Promise.resolve().then(() => order)`,
+			Want:  "Promise { [sync, job] }",
+		},
+		{
+			Input: `async function add(a, b) { return await a + await Promise.resolve(b) }; add(1, 2)`,
+			Want:  "Promise { 3 }",
+		},
+		{
+			Input: `async function f() { try { await Promise.reject("err") } catch (e) { return "caught " + e } }; f()`,
+			Want:  "Promise { caught err }",
+		},
+		{
+			Input: `const f = async () => { throw new TypeError("bad") }; f().catch(e => e.name)`,
+			Want:  "Promise { TypeError }",
+		},
+		{
+			Input: `Promise.all([1, Promise.resolve(2), new Promise(r => r(3))])`,
+			Want:  "Promise { [1, 2, 3] }",
+		},
+		{
+			Input: `Promise.allSettled([Promise.reject("x"), 1]).then(list => list.map(r => r.status))`,
+			Want:  "Promise { [rejected, fulfilled] }",
+		},
+		{
+			Input: `Promise.race([Promise.resolve("first"), Promise.resolve("second")])`,
+			Want:  "Promise { first }",
+		},
+		{
+			Input: `Promise.any([Promise.reject(1), Promise.reject(2)]).catch(e => [e.name, e.errors])`,
+			Want:  "Promise { [AggregateError, [1, 2]] }",
+		},
+		{
+			Input: `let done = false; Promise.reject("r").finally(() => done = true).catch(e => [e, done])`,
+			Want:  "Promise { [r, true] }",
+		},
+		{
+			Input: `[Promise.resolve(1) instanceof Promise, new Promise(() => {}) instanceof Promise, {} instanceof Promise]`,
+			Want:  "[true, true, false]",
+		},
+	}
+	runEvalCases(t, tests)
+
+	unhandled := []string{
+		`Promise.reject("x")`,
+		`async function f() { throw new TypeError("bad") }; f()`,
+		`Promise.resolve(1).then(() => { throw "late" })`,
+	}
+	for _, in := range unhandled {
+		_, err := EvalDefault(strings.NewReader(in))
+		if !errors.Is(err, ErrThrow) {
+			t.Errorf("%s: expected unhandled rejection, got %v", in, err)
+		}
+	}
+}
+
+func TestLoop(t *testing.T) {
+	ev := env.EnclosedEnv(Default())
+	p, resolve, _ := LoopOf(ev).NewPromise()
+	ev.Define("job", p, true)
+
+	go resolve(value.CreateString("done"))
+
+	v, err := Eval(strings.NewReader(`job.then(v => "host " + v)`), ev)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, want := v.String(), "Promise { host done }"; got != want {
+		t.Errorf("results mismatched! want %s, got %s", want, got)
+	}
+}
+
+func TestTimer(t *testing.T) {
+	var (
+		ev    = env.EnclosedEnv(Default())
+		start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		clock = NewVirtualClock(start)
+	)
+	LoopOf(ev).SetClock(clock)
+
+	script := `
+		const log = []
+		setTimeout(() => log.push("hour"), 3600000)
+		setTimeout((a, b) => log.push(a + b), 0, "a", "b")
+		queueMicrotask(() => log.push("micro"))
+		let count = 0
+		const id = setInterval(() => { count++; log.push("tick"); if (count == 3) { clearInterval(id) } }, 60000)
+		clearTimeout(setTimeout(() => log.push("cancelled"), 10))
+		const sleep = (ms) => new Promise(resolve => setTimeout(resolve, ms))
+		const done = (async () => { await sleep(7200000); return log })()
+		log.push("sync")
+		done
+	`
+	v, err := Eval(strings.NewReader(script), ev)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, want := v.String(), "Promise { [sync, micro, ab, tick, tick, tick, hour] }"; got != want {
+		t.Errorf("results mismatched! want %s, got %s", want, got)
+	}
+	if got := clock.Now().Sub(start); got != 2*time.Hour {
+		t.Errorf("virtual time mismatched! want %s, got %s", 2*time.Hour, got)
+	}
+
+	_, err = Eval(strings.NewReader(`setTimeout(() => { throw new TypeError("boom") }, 10)`), ev)
+	if !errors.Is(err, ErrThrow) {
+		t.Errorf("expected uncaught exception, got %v", err)
+	}
+}
+
+func TestRegExp(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `const m = /(\d{4})-(\d{2})/.exec("on 2024-03"); [m[0], m[1], m[2], m.index]`,
+			Want:  "[2024-03, 2024, 03, 3]",
+		},
+		{
+			Input: `const { year, month } = "2024-03".match(/(?<year>\d{4})-(?<month>\d{2})/).groups; [year, month]`,
+			Want:  "[2024, 03]",
+		},
+		{
+			Input: `const re = /o/g; [re.test("foo"), re.lastIndex, re.test("foo"), re.test("foo"), re.lastIndex]`,
+			Want:  "[true, 2, true, false, 0]",
+		},
+		{
+			Input: `["a1b22".match(/\d+/g), "abc".match(/\d/), "abc".search(/c/)]`,
+			Want:  "[[1, 22], null, 2]",
+		},
+		{
+			Input: `["John Smith".replace(/(\w+) (\w+)/, "$2 $1"), "a-b-c".replace(/-/g, m => "+"), "x=1".replaceAll(/(?<k>\w)=/g, "$<k>:")]`,
+			Want:  "[Smith John, a+b+c, x:1]",
+		},
+		{
+			Input: `const res = []; for (const m of "a=1;b=2".matchAll(/(\w)=(\d)/g)) { res.push(m[1] + m[2]) }; res`,
+			Want:  "[a1, b2]",
+		},
+		{
+			Input: `["a, b;c".split(/[,;]\s*/), "a1b".split(/(\d)/), "a,b,c".split(",", 2)]`,
+			Want:  "[[a, b, c], [a, 1, b], [a, b]]",
+		},
+		{
+			Input: `const re = new RegExp("h(i)", "gi"); [re.source, re.flags, re.global, re.test("HI")]`,
+			Want:  "[h(i), gi, true, true]",
+		},
+		{
+			Input: `const r = []; for (const p of ["(a)\\1", "a(?=b)", "(?<!a)b"]) { try { new RegExp(p) } catch (e) { r.push(e.name) } }; r`,
+			Want:  "[SyntaxError, SyntaxError, SyntaxError]",
+		},
+		{
+			Input: `const n = 10; [n / 2 / 5, /=/.test("a=b")]`,
+			Want:  "[1, true]",
+		},
+		{
+			Input: `const a = /^a/g; const b = /\bb/g; b.lastIndex = 1; const c = /a/y; c.lastIndex = 1; [a.test("aa"), a.test("aa"), b.test("ab"), c.test("ba"), c.lastIndex]`,
+			Want:  "[true, false, false, true, 2]",
+		},
+		{
+			Input: `const r = /\d/g; const m = r.exec("é1é2"); ["é1".match(/1/).index, m.index, r.lastIndex, r.exec("é1é2").index, "😀x".search(/x/)]`,
+			Want:  "[1, 1, 2, 3, 2]",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestLogical(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `const a = null; [a && a.b, 0 || "d", "" ?? "n", null ?? "n", 1 && 2]`,
+			Want:  "[null, d, , n, 2]",
+		},
+		{
+			Input: `let calls = 0; const f = () => { calls++; return 1 }; "v" ?? f(); true || f(); false && f(); [calls, null ?? f(), calls]`,
+			Want:  "[0, 1, 1]",
+		},
+		{
+			Input: `const o = { n: 0, s: "s", u: null }; o.n ||= 5; o.s &&= "t"; o.u ??= "u"; [o.n, o.s, o.u]`,
+			Want:  "[5, t, u]",
+		},
+		{
+			Input: `const c = 1; c ||= 2; let d = null; d &&= 2; [c, d]`,
+			Want:  "[1, null]",
+		},
+		{
+			Input: `let calls = 0; const f = () => { calls++; return "f" }; [true ? "t" : f(), false ? f() : "e", calls]`,
+			Want:  "[t, e, 0]",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestParams(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `function sum(...nums) { return nums.reduce((a, b) => a + b, 0) }; [sum(), sum(1, 2, 3), sum(...[4, 5], 6)]`,
+			Want:  "[0, 6, 15]",
+		},
+		{
+			Input: `function head(a, ...rest) { return [a, rest, arguments.length] }; [head(1, 2, 3), head()]`,
+			Want:  "[[1, [2, 3], 3], [undefined, [], 0]]",
+		},
+		{
+			Input: `const f = (a = 1, {b, c: d = 4}, [e, ...g], ...h) => [a, b, d, e, g, h]; f(undefined, {b: 2}, [3, 4, 5], 6, 7)`,
+			Want:  "[1, 2, 4, 3, [4, 5], [6, 7]]",
+		},
+		{
+			Input: `function f(a, b = a + 1) { return [a, b, arguments[0]] }; [f(1), f(1, null)]`,
+			Want:  "[[1, 2, 1], [1, null, 1]]",
+		},
+		{
+			Input: `function f(a, b = 1, c) {}; function g(...r) {}; [f.length, g.length, ((a, b) => a).length]`,
+			Want:  "[1, 0, 2]",
+		},
+		{
+			Input: `class P { constructor(...xs) { this.xs = xs } }; class Q extends P { constructor(...a) { super(...a, 9) } }; [new P(...[1, 2]).xs, new Q(3).xs]`,
+			Want:  "[[1, 2], [3, 9]]",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestArrayCallback(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `[[[1, 2], [3, 4]].map(([a, b]) => a + b), [{x: 1}, {x: 2}].map(({x}) => x), [1, 2].map((...xs) => xs.length)]`,
+			Want:  "[[3, 7], [1, 2], [3, 3]]",
+		},
+		{
+			Input: `const o = {n: 10}; function add(v) { return this.n + v }; [[1].map(add.bind(o)), [1, 2].map(function(v) { return this.n * v }, o)]`,
+			Want:  "[[11], [10, 20]]",
+		},
+		{
+			Input: `[[1, 2, 3].reduce((a, b) => a + b), [1, 2, 3].reduce((acc, v, i, arr) => acc + i * arr.length, 0), [1, 2].flatMap(v => [v, v * 2])]`,
+			Want:  "[6, 9, [1, 2, 2, 4]]",
+		},
+		{
+			Input: `try { [1].map(v => { throw "boom" }) } catch (e) { e }`,
+			Want:  "boom",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestObjectLiteral(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `const k = "c"; const o = {b: 1, a: 2, [k + "1"]: 3, b: 4}; [Object.keys(o), o.b, o.c1]`,
+			Want:  "[[b, a, c1], 4, 3]",
+		},
+		{
+			Input: `const x = 1; const y = [2]; const o = {x, y, x: x + 1}; [Object.keys(o), o.x, o.y]`,
+			Want:  "[[x, y], 2, [2]]",
+		},
+		{
+			Input: `const o = {n: 2, twice() { return this.n * 2 }, ["get" + "N"]() { return this.n }}; [o.twice(), o.getN(), o.getN.name]`,
+			Want:  "[4, 2, getN]",
+		},
+		{
+			Input: `const base = {a: 1, b: 2}; const o = {b: 0, ...base, c: 3, a: 4}; [Object.keys(o), o.a, o.b]`,
+			Want:  "[[b, a, c], 4, 2]",
+		},
+		{
+			Input: `const o = {...[7, 8], ...null, ...undefined, ..."z"}; [Object.keys(o), o[0], o[1]]`,
+			Want:  "[[0, 1], z, 8]",
+		},
+		{
+			Input: `const o = {v: 1, get w() { return this.v + 1 }, set w(x) { this.v = x }}; o.w = 5; const c = {...o}; [o.w, c.w, Object.keys(c)]`,
+			Want:  "[6, 6, [v, w]]",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestDestructuring(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `let a = 1; let b = 2; [a, b] = [b, a]; [a, b]`,
+			Want:  "[2, 1]",
+		},
+		{
+			Input: `const o = {x: 1, y: {z: [3]}, w: 5}; const {x: r, y: {z: [f, s = 9]}, ...others} = o; [r, f, s, Object.keys(others)]`,
+			Want:  "[1, 3, 9, [w]]",
+		},
+		{
+			Input: `const k = "w"; const {[k]: v, m = "d", n = 1} = {w: 5, n: null}; [v, m, n]`,
+			Want:  "[5, d, null]",
+		},
+		{
+			Input: `function* gen() { yield 1; yield 2; yield 3 }; const [g1, , ...gs] = gen(); const [c, ...cs] = "abc"; [g1, gs, c, cs]`,
+			Want:  "[1, [3], a, [b, c]]",
+		},
+		{
+			Input: `const t = {}; let p = 0; ({p, q: t.q = 2} = {p: 1}); [t.i, t["j"]] = [3, 4]; [p, t.q, t.i, t.j]`,
+			Want:  "[1, 2, 3, 4]",
+		},
+		{
+			Input: `const out = []; let k = 0; for ([k] of [[1], [2]]) { out.push(k) }; try { throw {code: 3} } catch ({code}) { out.push(code) }; out`,
+			Want:  "[1, 2, 3]",
+		},
+		{
+			Input: `const f = ({a: [x] = [5]} = {}) => x; [f(), f({a: [6]})]`,
+			Want:  "[5, 6]",
+		},
+		{
+			Input: `const names = []; try { const {a} = null } catch (e) { names.push(e.name) }; try { const [b] = 1 } catch (e) { names.push(e.name) }; names`,
+			Want:  "[TypeError, TypeError]",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestSymbol(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `const s = Symbol("id"); [(typeof s), s.toString(), s.description, s === s, s === Symbol("id"), Symbol().description]`,
+			Want:  "[symbol, Symbol(id), id, true, false, undefined]",
+		},
+		{
+			Input: `const k = Symbol.for("app"); [k === Symbol.for("app"), Symbol.keyFor(k), Symbol.keyFor(Symbol("app"))]`,
+			Want:  "[true, app, undefined]",
+		},
+		{
+			Input: `const s = Symbol("s"); const o = {[s]: 1, a: 2}; o[Symbol.for("t")] = 3; const c = {...o}; [o[s], o[Symbol.for("t")], c[s], Object.keys(o)]`,
+			Want:  "[1, 3, 1, [a]]",
+		},
+		{
+			Input: `class Range { constructor(n) { this.n = n }; *[Symbol.iterator]() { for (let i = 0; i < this.n; i++) { yield i } } }; const out = []; for (const i of new Range(2)) { out.push(i) }; const [a, ...b] = new Range(3); [out, [...new Range(3)], a, b]`,
+			Want:  "[[0, 1], [0, 1, 2], 0, [1, 2]]",
+		},
+		{
+			Input: `const it = {[Symbol.iterator]() { let n = 0; return {next: () => ({value: n, done: n++ >= 2})} }}; [...it]`,
+			Want:  "[0, 1]",
+		},
+		{
+			Input: `const m = {n: 5, [Symbol.toPrimitive](hint) { return hint == "number" ? this.n : "five" }}; [m * 2, -m, ` + "`${m}`" + `]`,
+			Want:  "[10, -5, five]",
+		},
+		{
+			Input: `class Tagged { get [Symbol.toStringTag]() { return "Tagged" } }; [({}).toString(), new Tagged().toString(), {[Symbol.toStringTag]: "Lit"}.toString()]`,
+			Want:  "[[object Object], [object Tagged], [object Lit]]",
+		},
+		{
+			Input: `const s = Symbol("s"); const t = Symbol("t"); const o = {[s]: 1, [t]: 2, a: 3}; const {[s]: v, ...rest} = o; [v, rest[s], rest[t], rest.a, s in o, s in rest, Symbol.iterator in o]`,
+			Want:  "[1, undefined, 2, 3, true, false, false]",
+		},
+		{
+			Input: `const it = [1, 2][Symbol.iterator](); [[][Symbol.iterator]().next().done, it.next().value, [..."ab"[Symbol.iterator]()], Symbol.iterator in []]`,
+			Want:  "[true, 1, [a, b], true]",
+		},
+		{
+			Input: `const out = []; try { new Symbol() } catch (e) { out.push(e.name) }; try { ` + "`${Symbol()}`" + ` } catch (e) { out.push(e.name) }; out`,
+			Want:  "[TypeError, TypeError]",
+		},
+		{
+			Input: `const k = Symbol("k"); const o = {}; Object.defineProperty(o, k, { value: 1 }); Object.defineProperty(o, Symbol.iterator, { value: function*() { yield 1; yield 2 } }); [o[k], Object.getOwnPropertyDescriptor(o, k).value, Object.getOwnPropertyDescriptors(o)[k].writable, Object.keys(o).length, [...o]]`,
+			Want:  "[1, 1, false, 0, [1, 2]]",
+		},
+		{
+			Input: "const o = {[Symbol.toStringTag]: \"Custom\"}; const n = {valueOf() { return 2 }, toString() { return \"n\" }}; let r; try { `${{toString() { return {} }}}` } catch (e) { r = e.name }; [`${o}`, `${{toString() { return \"x\" }}}`, `${{}}`, n + 1, `${n}`, r]",
+			Want:  "[[object Custom], x, [object Object], 3, n, TypeError]",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestHoisting(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `const r = add(1, 2); function add(a, b) { return a + b }; r`,
+			Want:  "3",
+		},
+		{
+			Input: `const before = v; var v = 3; [before, v]`,
+			Want:  "[undefined, 3]",
+		},
+		{
+			Input: `function f() { if (true) { var inner = 1 }; for (var i = 0; i < 3; i++) {}; return [inner, i, g()]; function g() { return "g" } }; f()`,
+			Want:  "[1, 3, g]",
+		},
+		{
+			Input: `var [p, q] = [1, 2]; for (var k of [7, 8]) {}; function shadow(a) { var a; return a }; [p, q, k, shadow(5)]`,
+			Want:  "[1, 2, 8, 5]",
+		},
+		{
+			Input: `let res; try { res = x } catch (e) { res = e.name }; let x = 1; res`,
+			Want:  "ReferenceError",
+		},
+		{
+			Input: `function later() { return y }; let res; try { later() } catch (e) { res = e.name }; const y = 2; [res, later()]`,
+			Want:  "[ReferenceError, 2]",
+		},
+		{
+			Input: `let res; try { new C() } catch (e) { res = e.name }; class C {}; res`,
+			Want:  "ReferenceError",
+		},
+		{
+			Input: `var x; function x() { return 1 }; function f(a) { function a() { return 2 }; return a() }; function g() { return 1 }; function g() { return 2 }; [(typeof x), f(1), g()]`,
+			Want:  "[function, 2, 2]",
+		},
+		{
+			Input: `const res = f(); export function f() { return 1 }; res`,
+			Want:  "1",
+		},
+		{
+			Input: `let res; try { new C() } catch (e) { res = e.name }; export class C {}; [res, new C() instanceof C]`,
+			Want:  "[ReferenceError, true]",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestUpdate(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `const o = { count: 1 }; [o.count++, o.count, ++o.count, o.count]`,
+			Want:  "[1, 2, 3, 3]",
+		},
+		{
+			Input: `let x = 5; [x--, x, --x, x]`,
+			Want:  "[5, 4, 3, 3]",
+		},
+		{
+			Input: `const arr = [1, 2, 3]; let i = 0; arr[i++] += 2; arr[i]--; [arr, i]`,
+			Want:  "[[3, 1, 3], 1]",
+		},
+		{
+			Input: `let calls = 0; const o = { n: 1 }; const get = () => { calls++; return o }; get().n *= 10; [o.n, calls]`,
+			Want:  "[10, 1]",
+		},
+		{
+			Input: `let x = 3; x **= 2; let y = -16; y >>>= 1; let b = 6; b &= 3; b |= 8; b <<= 1; [x, y, b]`,
+			Want:  "[9, 2147483640, 20]",
+		},
+		{
+			Input: `const arr = []; arr[2] = 1; const o = {}; o["k"] = 1; o.k ||= 2; o.n ??= 3; [arr, o.k, o.n]`,
+			Want:  "[[undefined, undefined, 1], 1, 3]",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestBitwise(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `[5 ^ 3, ~5, ~-1, 5 & 3, 5 | 3]`,
+			Want:  "[6, -6, 0, 1, 7]",
+		},
+		{
+			Input: `[-1 >>> 0, -16 >> 2, -16 >>> 28, 1 << 31, 1 << 32, 1 << 33, 5 >> 33]`,
+			Want:  "[4294967295, -4, 15, -2147483648, 1, 2, 2]",
+		},
+		{
+			Input: `[2147483648 | 0, 4294967296 | 0, 3.7 | 0, -3.7 | 0, undefined | 0, null ^ 1, true & 3, "12" | 1]`,
+			Want:  "[-2147483648, 0, 3, -3, 0, 1, 1, 13]",
+		},
+		{
+			Input: `[0xff, 0b101, 0o17, 0xFF & 0x0f, 0x7FFFFFFF + 1 | 0]`,
+			Want:  "[255, 5, 15, 15, -2147483648]",
+		},
+		{
+			Input: `let h = 0; for (const c of [104, 105]) { h = (h << 5) - h + c; h |= 0 }; let m = 6; m ^= 3; [h, m]`,
+			Want:  "[3329, 5]",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestOptional(t *testing.T) {
+	tests := []evalCase{
+		{
+			Input: `const a = null; const o = { b: { c: 1 } }; [a?.b, a?.b.c.d, o?.b.c, o.x?.y, o?.b?.c]`,
+			Want:  "[undefined, undefined, 1, undefined, 1]",
+		},
+		{
+			Input: `const a = undefined; const o = { list: [1, 2] }; [a?.[0], o.list?.[1], o.none?.[0]]`,
+			Want:  "[undefined, 2, undefined]",
+		},
+		{
+			Input: `const f = null; const o = { m: function() { return 42 } }; [f?.(1), o.m?.(), o.n?.(), o.n?.x(), "abc".toUpperCase?.()]`,
+			Want:  "[undefined, 42, undefined, undefined, ABC]",
+		},
+		{
+			Input: `let calls = 0; const f = () => { calls++; return 0 }; const a = null; a?.[f()]; a?.b(f()); calls`,
+			Want:  "0",
+		},
+	}
+	runEvalCases(t, tests)
+}
+
+func TestModule(t *testing.T) {
+	v, err := EvalFile("testdata/modules/main.js", env.EnclosedEnv(Default()))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := "[math, 3, 0, 1, 1, true, false, 3, 6, 4, 42, 7, TypeError]"
+	if got := v.String(); got != want {
+		t.Errorf("results mismatched! want %s, got %s", want, got)
+	}
+	_, err = EvalFile("testdata/modules/missing.js", env.EnclosedEnv(Default()))
+	if !errors.Is(err, ErrExport) {
+		t.Errorf("expected export error, got %v", err)
+	}
+}
+
+func TestResolver(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.js":       {Data: []byte(`import { greet } from "./lib/greet.js"; import { sqrt } from "std:math"; [greet("world"), sqrt(16)]`)},
+		"lib/greet.js":  {Data: []byte(`import { prefix } from "/lib/prefix.js"; export function greet(name) { return prefix + name }`)},
+		"lib/prefix.js": {Data: []byte(`export const prefix = "hello "`)},
+		"escape.js":     {Data: []byte(`import "../outside.js"`)},
+		"cycle/k.js":    {Data: []byte(`import { world } from "./l.js"; export function hello() { return "hello" }; world`)},
+		"cycle/l.js":    {Data: []byte(`import { hello } from "./k.js"; export const world = hello() + " world"`)},
+		"late.js":       {Data: []byte(`const res = prefix + "late"; import { prefix } from "./lib/prefix.js"; res`)},
+	}
+	std := map[string]map[string]value.Value{
+		"math": {
+			"sqrt": value.CreateBuiltin("sqrt", func(args ...value.Value) (value.Value, error) {
+				f, err := value.Coerce(args[0])
+				if err != nil {
+					return nil, err
+				}
+				return value.CreateFloat(math.Sqrt(f.(value.Float).Native())), nil
+			}),
+		},
+	}
+	res := ChainResolver(FSResolver(fsys), StdResolver(std))
+
+	v, err := EvalModule("main.js", env.EnclosedEnv(Default()), res)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, want := v.String(), "[hello world, 4]"; got != want {
+		t.Errorf("results mismatched! want %s, got %s", want, got)
+	}
+	for file, want := range map[string]string{
+		"cycle/k.js": "hello world",
+		"late.js":    "hello late",
+	} {
+		v, err := EvalModule(file, env.EnclosedEnv(Default()), res)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", file, err)
+			continue
+		}
+		if got := v.String(); got != want {
+			t.Errorf("%s: results mismatched! want %s, got %s", file, want, got)
+		}
+	}
+	_, err = EvalModule("escape.js", env.EnclosedEnv(Default()), res)
+	if !errors.Is(err, ErrModule) {
+		t.Errorf("expected module error, got %v", err)
+	}
+}
+
+func runEvalCases(t *testing.T, tests []evalCase) {
+	t.Helper()
+	for _, c := range tests {
+		v, err := EvalDefault(strings.NewReader(c.Input))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", c.Input, err)
+			continue
+		}
+		if got := v.String(); got != c.Want {
+			t.Errorf("%s: results mismatched! want %s, got %s", c.Input, c.Want, got)
+		}
+	}
+}
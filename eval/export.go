@@ -1,19 +1,200 @@
 package eval
 
 import (
+	"fmt"
+
 	"github.com/midbel/enjoy/ast"
 	"github.com/midbel/enjoy/env"
 	"github.com/midbel/enjoy/value"
 )
 
+const defaultIdent = "#default"
+
 func evalImport(i ast.ImportNode, ev env.Environ[value.Value]) (value.Value, error) {
-	return nil, nil
+	ctx, err := currentContext(ev)
+	if err != nil {
+		return nil, err
+	}
+	mod, err := ctx.Load(i.From)
+	if err != nil {
+		return nil, err
+	}
+	if i.Default != nil {
+		v, ok := i.Default.(ast.VarNode)
+		if !ok {
+			return nil, ErrEval
+		}
+		if err := ctx.Import(v.Ident, mod, "default"); err != nil {
+			return nil, err
+		}
+	}
+	switch n := i.Node.(type) {
+	case nil:
+	case ast.VarNode:
+		err = ctx.ImportNamespace(n.Ident, mod)
+	case ast.SeqNode:
+		for _, a := range n.Nodes {
+			a, ok := a.(ast.AliasNode)
+			if !ok {
+				return nil, ErrEval
+			}
+			ident := a.Alias
+			if ident == "" {
+				ident = a.Name
+			}
+			if err = ctx.Import(ident, mod, a.Name); err != nil {
+				break
+			}
+		}
+	default:
+		err = ErrEval
+	}
+	return value.Undefined(), err
 }
 
 func evalExport(i ast.ExportNode, ev env.Environ[value.Value]) (value.Value, error) {
-	return nil, nil
+	if i.Default {
+		ctx, err := currentContext(ev)
+		if err != nil {
+			return nil, err
+		}
+		res, err := eval(i.Node, ev)
+		if err != nil {
+			return nil, err
+		}
+		return value.Undefined(), ctx.Define(defaultIdent, res, true)
+	}
+	if _, ok := i.Node.(ast.SeqNode); ok {
+		return value.Undefined(), nil
+	}
+	return eval(i.Node, ev)
 }
 
 func evalExportFrom(i ast.ExportFromNode, ev env.Environ[value.Value]) (value.Value, error) {
-	return nil, nil
+	ctx, err := currentContext(ev)
+	if err != nil {
+		return nil, err
+	}
+	mod, err := ctx.Load(i.From)
+	if err != nil {
+		return nil, err
+	}
+	switch n := i.Node.(type) {
+	case nil:
+		ctx.module.ExportAll(mod)
+	case ast.VarNode:
+		err = ctx.module.Export(n.Ident, constBinding(mod.Namespace()))
+	case ast.SeqNode:
+		for _, a := range n.Nodes {
+			a, ok := a.(ast.AliasNode)
+			if !ok {
+				return nil, ErrEval
+			}
+			name := a.Alias
+			if name == "" {
+				name = a.Name
+			}
+			if mod.loaded && !mod.Has(a.Name) {
+				return nil, fmt.Errorf("%s: %s: %w", mod.Name, a.Name, ErrExport)
+			}
+			if err = ctx.module.Export(name, remoteBinding(a.Name, mod)); err != nil {
+				break
+			}
+		}
+	default:
+		err = ErrEval
+	}
+	return value.Undefined(), err
+}
+
+// linkModule declares the exports of a module, hoists its declarations and
+// binds its imports before any statement of its body runs, so that a module
+// importing it back in a cycle already sees its exported functions.
+func linkModule(nodes []ast.Node, ev env.Environ[value.Value]) error {
+	ctx, err := currentContext(ev)
+	if err != nil {
+		return err
+	}
+	for _, n := range nodes {
+		x, ok := n.(ast.ExportNode)
+		if !ok {
+			continue
+		}
+		if err := declareExport(x, ctx); err != nil {
+			return err
+		}
+	}
+	if err := hoistBlock(nodes, ev); err != nil {
+		return err
+	}
+	for _, n := range nodes {
+		var err error
+		switch x := n.(type) {
+		case ast.ImportNode:
+			_, err = evalImport(x, ev)
+		case ast.ExportFromNode:
+			_, err = evalExportFrom(x, ev)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func declareExport(n ast.ExportNode, ctx *context) error {
+	if n.Default {
+		return ctx.module.Export("default", localBinding(defaultIdent, ctx))
+	}
+	if seq, ok := n.Node.(ast.SeqNode); ok {
+		for _, a := range seq.Nodes {
+			a, ok := a.(ast.AliasNode)
+			if !ok {
+				return ErrEval
+			}
+			name := a.Alias
+			if name == "" {
+				name = a.Name
+			}
+			if err := ctx.module.Export(name, localBinding(a.Name, ctx)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, ident := range exportNames(n.Node) {
+		if err := ctx.module.Export(ident, localBinding(ident, ctx)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func localBinding(ident string, ev env.Environ[value.Value]) binding {
+	return func() (value.Value, error) {
+		return ev.Resolve(ident)
+	}
+}
+
+func remoteBinding(name string, mod *Module) binding {
+	return func() (value.Value, error) {
+		return mod.Resolve(name)
+	}
+}
+
+func exportNames(n ast.Node) []string {
+	switch n := n.(type) {
+	case ast.LetNode:
+		return bindingNames(n.Ident)
+	case ast.ConstNode:
+		return bindingNames(n.Ident)
+	case ast.VarDeclNode:
+		return bindingNames(n.Ident)
+	case ast.FuncNode:
+		return []string{n.Ident}
+	case ast.ClassNode:
+		return []string{n.Ident}
+	default:
+		return nil
+	}
 }
@@ -1,6 +1,10 @@
 package eval
 
 import (
+	"fmt"
+	"reflect"
+	"strconv"
+
 	"github.com/midbel/enjoy/ast"
 	"github.com/midbel/enjoy/env"
 	"github.com/midbel/enjoy/token"
@@ -8,6 +12,10 @@ import (
 )
 
 func evalBinary(n ast.BinaryNode, ev env.Environ[value.Value]) (value.Value, error) {
+	switch n.Op {
+	case token.And, token.Or, token.Nullish:
+		return evalLogical(n, ev)
+	}
 	left, err := eval(n.Left, ev)
 	if err != nil {
 		return nil, err
@@ -16,12 +24,35 @@ func evalBinary(n ast.BinaryNode, ev env.Environ[value.Value]) (value.Value, err
 	if err != nil {
 		return nil, err
 	}
-	switch n.Op {
-	case token.Nullish:
-		if value.IsNull(left) || value.IsUndefined(left) {
-			return right, nil
+	if left, right, err = primitiveOperands(n.Op, left, right, ev); err != nil {
+		return nil, err
+	}
+	return binaryValues(n.Op, left, right)
+}
+
+func primitiveOperands(op rune, left, right value.Value, ev env.Environ[value.Value]) (value.Value, value.Value, error) {
+	hint := "number"
+	switch op {
+	case token.Seq, token.Sne:
+		return left, right, nil
+	case token.Eq, token.Ne:
+		if isObject(left) == isObject(right) {
+			return left, right, nil
 		}
-		return left, nil
+		hint = "default"
+	case token.Add:
+		hint = "default"
+	}
+	left, err := toPrimitive(left, hint, ev)
+	if err != nil {
+		return nil, nil, err
+	}
+	right, err = toPrimitive(right, hint, ev)
+	return left, right, err
+}
+
+func binaryValues(op rune, left, right value.Value) (value.Value, error) {
+	switch op {
 	case token.Add:
 		return addValues(left, right)
 	case token.Sub:
@@ -38,6 +69,8 @@ func evalBinary(n ast.BinaryNode, ev env.Environ[value.Value]) (value.Value, err
 		return lshiftValues(left, right)
 	case token.Rshift:
 		return rshiftValues(left, right)
+	case token.Urshift:
+		return urshiftValues(left, right)
 	case token.Eq:
 		return cmpEq(left, right)
 	case token.Seq:
@@ -58,18 +91,110 @@ func evalBinary(n ast.BinaryNode, ev env.Environ[value.Value]) (value.Value, err
 		return bandValues(left, right)
 	case token.Bor:
 		return borValues(left, right)
+	case token.Bxor:
+		return bxorValues(left, right)
+	default:
+		return nil, value.ErrOperation
+	}
+}
+
+func evalLogical(n ast.BinaryNode, ev env.Environ[value.Value]) (value.Value, error) {
+	left, err := eval(n.Left, ev)
+	if err != nil {
+		return nil, err
+	}
+	if isShortCircuit(n.Op, left) {
+		return left, nil
+	}
+	return eval(n.Right, ev)
+}
+
+func isShortCircuit(op rune, v value.Value) bool {
+	switch op {
 	case token.And:
-		return value.CreateBool(left.True() && right.True()), nil
+		return !v.True()
 	case token.Or:
-		return value.CreateBool(left.True() || right.True()), nil
+		return v.True()
 	default:
-		return nil, value.ErrOperation
+		return !isNullish(v)
+	}
+}
+
+func evalInstanceOf(n ast.InstanceOfNode, ev env.Environ[value.Value]) (value.Value, error) {
+	left, err := eval(n.Left, ev)
+	if err != nil {
+		return nil, err
+	}
+	right, err := eval(n.Right, ev)
+	if err != nil {
+		return nil, err
+	}
+	if !isCallable(right) {
+		return nil, fmt.Errorf("instanceof: %s is not callable: %w", right, value.ErrOperation)
+	}
+	p, err := value.Get(right, "prototype")
+	if err != nil {
+		return nil, err
+	}
+	proto, ok := p.(*value.Object)
+	if !ok {
+		return nil, fmt.Errorf("instanceof: invalid prototype: %w", value.ErrOperation)
+	}
+	obj, ok := left.(interface{ Prototype() *value.Object })
+	if !ok {
+		return value.CreateBool(false), nil
+	}
+	for o := obj.Prototype(); o != nil; o = o.Prototype() {
+		if o == proto {
+			return value.CreateBool(true), nil
+		}
+	}
+	return value.CreateBool(false), nil
+}
+
+func evalIn(n ast.InNode, ev env.Environ[value.Value]) (value.Value, error) {
+	left, err := eval(n.Left, ev)
+	if err != nil {
+		return nil, err
+	}
+	right, err := eval(n.Right, ev)
+	if err != nil {
+		return nil, err
+	}
+	if sym, ok := left.(*value.Symbol); ok {
+		if !isObject(right) {
+			return nil, fmt.Errorf("in: %s is not an object: %w", right, value.ErrOperation)
+		}
+		if obj, ok := right.(*value.Object); ok {
+			return value.CreateBool(obj.HasSymbol(sym)), nil
+		}
+		return value.CreateBool(!value.IsUndefined(getSymbol(right, sym))), nil
+	}
+	prop := left.String()
+	switch r := right.(type) {
+	case *value.Object:
+		return value.CreateBool(r.Has(prop)), nil
+	case *value.Array:
+		if prop == "length" {
+			return value.CreateBool(true), nil
+		}
+		ix, err := strconv.Atoi(prop)
+		return value.CreateBool(err == nil && ix >= 0 && ix < r.Len()), nil
+	default:
+		return nil, fmt.Errorf("in: %s is not an object: %w", right, value.ErrOperation)
 	}
-	return nil, nil
 }
 
 func evalUnary(n ast.UnaryNode, ev env.Environ[value.Value]) (value.Value, error) {
+	switch n.Op {
+	case token.Increment, token.Decrement:
+		_, v, err := updateValue(n.Op, n.Expr, ev)
+		return v, err
+	}
 	v, err := eval(n.Expr, ev)
+	if err == nil && n.Op != token.Not {
+		v, err = toPrimitive(v, "number", ev)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -80,71 +205,201 @@ func evalUnary(n ast.UnaryNode, ev env.Environ[value.Value]) (value.Value, error
 		return value.Reverse(v)
 	case token.Not:
 		return value.CreateBool(!v.True()), nil
+	case token.Bnot:
+		return value.ToNumber(v).Bnot(), nil
+	default:
+		return nil, value.ErrOperation
+	}
+}
+
+func evalPostfix(n ast.PostfixNode, ev env.Environ[value.Value]) (value.Value, error) {
+	old, _, err := updateValue(n.Op, n.Expr, ev)
+	return old, err
+}
+
+func updateValue(op rune, n ast.Node, ev env.Environ[value.Value]) (value.Value, value.Value, error) {
+	ref, err := evalReference(n, ev)
+	if err != nil {
+		return nil, nil, err
+	}
+	old, err := ref.get()
+	if err != nil {
+		return nil, nil, err
+	}
+	if old, err = value.Coerce(old); err != nil {
+		return nil, nil, err
+	}
+	var res value.Value
+	switch op {
 	case token.Increment:
-		v, err := value.Increment(v)
-		if err == nil {
-			i, ok := n.Expr.(ast.VarNode)
-			if !ok {
-				return nil, ErrEval
-			}
-			if err := ev.Assign(i.Ident, v); err != nil {
-				return nil, err
-			}
-		}
-		return v, err
+		res, err = value.Increment(old)
 	case token.Decrement:
-		v, err := value.Decrement(v)
-		if err == nil {
-			i, ok := n.Expr.(ast.VarNode)
-			if !ok {
-				return nil, ErrEval
-			}
-			if err := ev.Assign(i.Ident, v); err != nil {
-				return nil, err
-			}
-		}
-		return v, err
+		res, err = value.Decrement(old)
 	default:
-		return nil, value.ErrOperation
+		return nil, nil, value.ErrOperation
+	}
+	if err != nil {
+		return nil, nil, err
 	}
+	return old, res, ref.set(res)
 }
 
 func evalAssign(n ast.AssignNode, ev env.Environ[value.Value]) (value.Value, error) {
-	v, err := eval(n.Expr, ev)
+	switch n.Op {
+	case 0, token.Assign:
+		v, err := eval(n.Expr, ev)
+		if err != nil {
+			return nil, err
+		}
+		return v, assignValue(n.Ident, v, ev)
+	}
+	ref, err := evalReference(n.Ident, ev)
+	if err != nil {
+		return nil, err
+	}
+	old, err := ref.get()
+	if err != nil {
+		return nil, err
+	}
+	var res value.Value
+	switch n.Op {
+	case token.And, token.Or, token.Nullish:
+		if isShortCircuit(n.Op, old) {
+			return old, nil
+		}
+		res, err = eval(n.Expr, ev)
+	default:
+		var right value.Value
+		if right, err = eval(n.Expr, ev); err == nil {
+			old, right, err = primitiveOperands(n.Op, old, right, ev)
+		}
+		if err == nil {
+			res, err = binaryValues(n.Op, old, right)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
-	switch ident := n.Ident.(type) {
+	return res, ref.set(res)
+}
+
+type reference struct {
+	get func() (value.Value, error)
+	set func(value.Value) error
+}
+
+func evalReference(n ast.Node, ev env.Environ[value.Value]) (reference, error) {
+	var ref reference
+	switch n := n.(type) {
 	case ast.VarNode:
-		err = ev.Assign(ident.Ident, v)
+		ref.get = func() (value.Value, error) {
+			return ev.Resolve(n.Ident)
+		}
+		ref.set = func(v value.Value) error {
+			return ev.Assign(n.Ident, v)
+		}
+	case ast.MemberNode:
+		id, ok := n.Next.(ast.VarNode)
+		if !ok {
+			return ref, ErrEval
+		}
+		obj, err := eval(n.Curr, ev)
+		if err != nil {
+			return ref, err
+		}
+		ref.get = func() (value.Value, error) {
+			return value.Get(obj, id.Ident)
+		}
+		ref.set = func(v value.Value) error {
+			return value.Set(obj, id.Ident, v)
+		}
+	case ast.IndexNode:
+		obj, err := eval(n.Expr, ev)
+		if err != nil {
+			return ref, err
+		}
+		ix, err := eval(n.Index, ev)
+		if err != nil {
+			return ref, err
+		}
+		ref.get = func() (value.Value, error) {
+			return value.At(obj, ix)
+		}
+		ref.set = func(v value.Value) error {
+			return value.SetAt(obj, ix, v)
+		}
+	default:
+		return ref, ErrEval
+	}
+	return ref, nil
+}
+
+func assignValue(n ast.Node, v value.Value, ev env.Environ[value.Value]) error {
+	switch ident := n.(type) {
+	case ast.VarNode:
+		return ev.Assign(ident.Ident, v)
 	case ast.MemberNode:
 		obj, err := eval(ident.Curr, ev)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		id, ok := ident.Next.(ast.VarNode)
 		if !ok {
-			return nil, ErrEval
+			return ErrEval
+		}
+		if _, ok := ident.Curr.(ast.SuperNode); ok {
+			return setSuper(obj, id.Ident, v, ev)
+		}
+		return value.Set(obj, id.Ident, v)
+	case ast.IndexNode:
+		obj, err := eval(ident.Expr, ev)
+		if err != nil {
+			return err
 		}
-		err = value.Set(obj, id.Ident, v)
+		ix, err := eval(ident.Index, ev)
+		if err != nil {
+			return err
+		}
+		return value.SetAt(obj, ix, v)
+	case ast.BindingArrayNode, ast.BindingObjectNode:
+		assign := func(n ast.Node, v value.Value) error {
+			return assignValue(n, v, ev)
+		}
+		return bindPattern(ident, v, ev, assign)
 	default:
-		return nil, ErrEval
+		return ErrEval
 	}
-	return v, err
 }
 
 func strictEqual(fst, snd value.Value) (value.Value, error) {
-	if fst.Type() == snd.Type() {
+	if fst.Type() != snd.Type() {
 		return value.CreateBool(false), nil
 	}
+	if _, ok := fst.(value.Comparable); !ok {
+		return value.CreateBool(sameValue(fst, snd)), nil
+	}
 	return cmpEq(fst, snd)
 }
 
 func strictNotEqual(fst, snd value.Value) (value.Value, error) {
-	if fst.Type() != snd.Type() {
-		return value.CreateBool(true), nil
+	res, err := strictEqual(fst, snd)
+	if err != nil {
+		return nil, err
+	}
+	return value.CreateBool(!res.True()), nil
+}
+
+func sameValue(fst, snd value.Value) bool {
+	switch x := fst.(type) {
+	case value.Func:
+		y, ok := snd.(value.Func)
+		return ok && x.Props != nil && x.Props == y.Props
+	default:
+		if reflect.ValueOf(fst).Kind() != reflect.Pointer {
+			return false
+		}
+		return fst == snd
 	}
-	return cmpNe(fst, snd)
 }
 
 func cmpEq(fst, snd value.Value) (value.Value, error) {
@@ -232,41 +487,25 @@ func divValues(fst, snd value.Value) (value.Value, error) {
 }
 
 func lshiftValues(fst, snd value.Value) (value.Value, error) {
-	a, ok := fst.(interface {
-		Lshift(value.Value) (value.Value, error)
-	})
-	if !ok {
-		return nil, value.ErrOperation
-	}
-	return a.Lshift(snd)
+	return value.ToNumber(fst).Lshift(snd)
 }
 
 func rshiftValues(fst, snd value.Value) (value.Value, error) {
-	a, ok := fst.(interface {
-		Rshift(value.Value) (value.Value, error)
-	})
-	if !ok {
-		return nil, value.ErrOperation
-	}
-	return a.Rshift(snd)
+	return value.ToNumber(fst).Rshift(snd)
+}
+
+func urshiftValues(fst, snd value.Value) (value.Value, error) {
+	return value.ToNumber(fst).Urshift(snd)
 }
 
 func bandValues(fst, snd value.Value) (value.Value, error) {
-	a, ok := fst.(interface {
-		Band(value.Value) (value.Value, error)
-	})
-	if !ok {
-		return nil, value.ErrOperation
-	}
-	return a.Band(snd)
+	return value.ToNumber(fst).Band(snd)
+}
+
+func bxorValues(fst, snd value.Value) (value.Value, error) {
+	return value.ToNumber(fst).Bxor(snd)
 }
 
 func borValues(fst, snd value.Value) (value.Value, error) {
-	a, ok := fst.(interface {
-		Bor(value.Value) (value.Value, error)
-	})
-	if !ok {
-		return nil, value.ErrOperation
-	}
-	return a.Bor(snd)
+	return value.ToNumber(fst).Bor(snd)
 }
@@ -0,0 +1,91 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/midbel/enjoy/env"
+	"github.com/midbel/enjoy/value"
+)
+
+type boundFunc struct {
+	target value.Value
+	this   value.Value
+	args   []value.Value
+}
+
+func (_ *boundFunc) True() bool {
+	return true
+}
+
+func (_ *boundFunc) Type() string {
+	return "function"
+}
+
+func (b *boundFunc) String() string {
+	return fmt.Sprintf("bound %s", b.target)
+}
+
+func (b *boundFunc) Get(prop string) (value.Value, error) {
+	switch prop {
+	case "name":
+		name, err := value.Get(b.target, "name")
+		if err != nil {
+			return nil, err
+		}
+		return value.CreateString("bound " + name.String()), nil
+	case "length":
+		n, err := value.Get(b.target, "length")
+		if err != nil {
+			return nil, err
+		}
+		var length int
+		if n, err := value.Coerce(n); err == nil {
+			if f, ok := n.(value.Float); ok && !math.IsNaN(f.Native()) {
+				length = int(f.Native())
+			}
+		}
+		return value.CreateFloat(float64(max(length-len(b.args), 0))), nil
+	default:
+		return value.Undefined(), nil
+	}
+}
+
+func (b *boundFunc) Invoke(_ value.Value, args []value.Value) (value.Value, error) {
+	return value.Invoke(b.target, b.this, b.arguments(args))
+}
+
+func (b *boundFunc) arguments(args []value.Value) []value.Value {
+	return append(slices.Clone(b.args), args...)
+}
+
+func callFuncMethod(fn value.Value, name string, args []value.Value, ev env.Environ[value.Value]) (value.Value, error) {
+	this := value.Undefined()
+	if len(args) > 0 {
+		this, args = args[0], args[1:]
+	}
+	switch name {
+	case "call":
+		return callWith(fn, this, args, ev)
+	case "apply":
+		var list []value.Value
+		if len(args) > 0 && !value.IsUndefined(args[0]) && !value.IsNull(args[0]) {
+			arr, ok := args[0].(*value.Array)
+			if !ok {
+				return nil, fmt.Errorf("apply: arguments should be an array: %w", value.ErrOperation)
+			}
+			list, _ = arr.Spread()
+		}
+		return callWith(fn, this, list, ev)
+	case "bind":
+		b := &boundFunc{
+			target: fn,
+			this:   this,
+			args:   slices.Clone(args),
+		}
+		return b, nil
+	default:
+		return nil, fmt.Errorf("%s is not a function: %w", name, value.ErrOperation)
+	}
+}
@@ -0,0 +1,363 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/midbel/enjoy/ast"
+	"github.com/midbel/enjoy/env"
+	"github.com/midbel/enjoy/value"
+)
+
+const generatorIdent = "#generator"
+
+type genState int
+
+const (
+	genStart genState = iota
+	genSuspended
+	genRunning
+	genDone
+)
+
+type resumeMode int
+
+const (
+	resumeNext resumeMode = iota
+	resumeThrow
+	resumeReturn
+)
+
+type resumeMsg struct {
+	mode  resumeMode
+	value value.Value
+}
+
+type yieldMsg struct {
+	value value.Value
+	done  bool
+	err   error
+}
+
+type generator struct {
+	fn     value.Func
+	env    env.Environ[value.Value]
+	async  bool
+	state  genState
+	ret    value.Value
+	resume chan resumeMsg
+	yield  chan yieldMsg
+	cancel <-chan struct{}
+}
+
+func createGenerator(fn value.Func, ev env.Environ[value.Value]) *generator {
+	g := &generator{
+		fn:     fn,
+		env:    ev,
+		resume: make(chan resumeMsg),
+		yield:  make(chan yieldMsg),
+	}
+	if l := LoopOf(ev); l != nil {
+		g.cancel = l.done
+	}
+	ev.Define(generatorIdent, g, true)
+	return g
+}
+
+func currentGenerator(ev env.Environ[value.Value]) (*generator, error) {
+	v, err := ev.Resolve(generatorIdent)
+	if err != nil {
+		return nil, fmt.Errorf("yield outside of generator: %w", ErrEval)
+	}
+	g, ok := v.(*generator)
+	if !ok || g.state != genRunning {
+		return nil, fmt.Errorf("yield outside of generator: %w", ErrEval)
+	}
+	return g, nil
+}
+
+func (g *generator) Get(prop string) (value.Value, error) {
+	var mode resumeMode
+	switch prop {
+	case "next":
+		mode = resumeNext
+	case "throw":
+		mode = resumeThrow
+	case "return":
+		mode = resumeReturn
+	default:
+		return value.Undefined(), nil
+	}
+	fn := func(args ...value.Value) (value.Value, error) {
+		arg := value.Undefined()
+		if len(args) > 0 {
+			arg = args[0]
+		}
+		v, done, err := g.send(mode, arg)
+		if err != nil {
+			return nil, err
+		}
+		return value.IterResult(v, done), nil
+	}
+	return value.CreateBuiltin(prop, fn), nil
+}
+
+func (g *generator) Iterate() value.Iterator {
+	return g
+}
+
+func (g *generator) Next() (value.Value, bool, error) {
+	return g.send(resumeNext, value.Undefined())
+}
+
+func (g *generator) Close() error {
+	if g.state != genSuspended {
+		g.state = genDone
+		return nil
+	}
+	_, _, err := g.send(resumeReturn, value.Undefined())
+	return err
+}
+
+func (g *generator) Spread() ([]value.Value, error) {
+	var list []value.Value
+	for {
+		v, done, err := g.Next()
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			break
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+func (_ *generator) True() bool {
+	return true
+}
+
+func (_ *generator) Type() string {
+	return "object"
+}
+
+func (_ *generator) String() string {
+	return "[object Generator]"
+}
+
+func (g *generator) send(mode resumeMode, v value.Value) (value.Value, bool, error) {
+	switch g.state {
+	case genRunning:
+		return nil, false, fmt.Errorf("generator is already running: %w", value.ErrOperation)
+	case genStart:
+		if mode == resumeNext {
+			go g.run()
+			break
+		}
+		g.state = genDone
+		return g.send(mode, v)
+	case genDone:
+		switch mode {
+		case resumeThrow:
+			return nil, false, ThrowError{
+				Value: v,
+			}
+		case resumeReturn:
+			return v, true, nil
+		default:
+			return value.Undefined(), true, nil
+		}
+	}
+	g.state = genRunning
+	var msg yieldMsg
+	select {
+	case g.resume <- resumeMsg{mode: mode, value: v}:
+		select {
+		case msg = <-g.yield:
+		case <-g.cancel:
+			msg.done = true
+		}
+	case <-g.cancel:
+		msg.done = true
+	}
+	if msg.value == nil && msg.err == nil {
+		msg.value = value.Undefined()
+	}
+	if msg.done {
+		g.state = genDone
+	} else {
+		g.state = genSuspended
+	}
+	return msg.value, msg.done, msg.err
+}
+
+func (g *generator) run() {
+	select {
+	case <-g.resume:
+	case <-g.cancel:
+		return
+	}
+	res, err := eval(g.fn.Body, g.env)
+	switch {
+	case errors.Is(err, ErrReturn):
+		err = nil
+		if res == nil {
+			res = g.ret
+		}
+	case err == nil && !g.fn.Arrow:
+		res = value.Undefined()
+	}
+	if res == nil {
+		res = value.Undefined()
+	}
+	msg := yieldMsg{
+		value: res,
+		done:  true,
+		err:   throwError(err, g.env),
+	}
+	select {
+	case g.yield <- msg:
+	case <-g.cancel:
+	}
+}
+
+// suspend hands v to the caller and waits to be resumed. Once the owning
+// loop is closed, nobody will resume the generator anymore: the body is then
+// unwound as if return had been called so that its goroutine can exit.
+func (g *generator) suspend(v value.Value) resumeMsg {
+	cancelled := resumeMsg{
+		mode:  resumeReturn,
+		value: value.Undefined(),
+	}
+	select {
+	case g.yield <- yieldMsg{value: v}:
+	case <-g.cancel:
+		return cancelled
+	}
+	select {
+	case msg := <-g.resume:
+		return msg
+	case <-g.cancel:
+		return cancelled
+	}
+}
+
+func (g *generator) resumeWith(msg resumeMsg) (value.Value, error) {
+	switch msg.mode {
+	case resumeThrow:
+		return nil, ThrowError{
+			Value: msg.value,
+		}
+	case resumeReturn:
+		g.ret = msg.value
+		return msg.value, ErrReturn
+	default:
+		return msg.value, nil
+	}
+}
+
+func evalYield(n ast.YieldNode, ev env.Environ[value.Value]) (value.Value, error) {
+	g, err := currentGenerator(ev)
+	if err != nil {
+		return nil, err
+	}
+	if g.async {
+		return nil, fmt.Errorf("yield not allowed in async function: %w", ErrEval)
+	}
+	v := value.Undefined()
+	if n.Node != nil {
+		if v, err = eval(n.Node, ev); err != nil {
+			return nil, err
+		}
+	}
+	if n.Delegate {
+		return delegateYield(g, v, ev)
+	}
+	return g.resumeWith(g.suspend(v))
+}
+
+func delegateYield(g *generator, v value.Value, ev env.Environ[value.Value]) (value.Value, error) {
+	if inner, ok := v.(*generator); ok {
+		msg := resumeMsg{
+			mode:  resumeNext,
+			value: value.Undefined(),
+		}
+		for {
+			v, done, err := inner.send(msg.mode, msg.value)
+			if err != nil {
+				return nil, err
+			}
+			if done {
+				if msg.mode == resumeReturn {
+					return g.resumeWith(msg)
+				}
+				return v, nil
+			}
+			msg = g.suspend(v)
+		}
+	}
+	it, err := getIterator(v, ev)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		v, done, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return value.Undefined(), nil
+		}
+		msg := g.suspend(v)
+		if msg.mode != resumeNext {
+			closeIterator(it)
+			return g.resumeWith(msg)
+		}
+	}
+}
+
+func execAsyncFunc(fn value.Func, ev env.Environ[value.Value]) (value.Value, error) {
+	loop := LoopOf(ev)
+	if loop == nil {
+		return nil, fmt.Errorf("%s: no event loop available for async function: %w", fn.Ident, ErrEval)
+	}
+	var (
+		p    = createPromise(loop)
+		g    = createGenerator(fn, ev)
+		step func(resumeMode, value.Value)
+	)
+	g.async = true
+	step = func(mode resumeMode, v value.Value) {
+		res, done, err := g.send(mode, v)
+		if err != nil {
+			p.reject(thrownValue(err, ev))
+			return
+		}
+		if done {
+			p.resolve(res)
+			return
+		}
+		next := func(v value.Value) {
+			step(resumeNext, v)
+		}
+		fail := func(v value.Value) {
+			step(resumeThrow, v)
+		}
+		promiseResolve(loop, res).subscribe(next, fail)
+	}
+	step(resumeNext, value.Undefined())
+	return p, nil
+}
+
+func evalAwait(n ast.AwaitNode, ev env.Environ[value.Value]) (value.Value, error) {
+	g, err := currentGenerator(ev)
+	if err != nil || !g.async {
+		return nil, fmt.Errorf("await is only valid in async functions: %w", ErrEval)
+	}
+	v, err := eval(n.Node, ev)
+	if err != nil {
+		return nil, err
+	}
+	return g.resumeWith(g.suspend(v))
+}
@@ -0,0 +1,162 @@
+package eval
+
+import (
+	"errors"
+
+	"github.com/midbel/enjoy/ast"
+	"github.com/midbel/enjoy/env"
+	"github.com/midbel/enjoy/value"
+)
+
+func hoistVars(n ast.Node, ev env.Environ[value.Value]) error {
+	for _, ident := range varNames(n) {
+		err := ev.Define(ident, value.Undefined(), false)
+		if err != nil && !errors.Is(err, env.ErrDefined) {
+			return err
+		}
+	}
+	return nil
+}
+
+func hoistBlock(nodes []ast.Node, ev env.Environ[value.Value]) error {
+	for _, n := range nodes {
+		if x, ok := n.(ast.ExportNode); ok && !x.Default {
+			n = x.Node
+		}
+		var names []string
+		switch n := n.(type) {
+		case ast.LetNode:
+			names = bindingNames(n.Ident)
+		case ast.ConstNode:
+			names = bindingNames(n.Ident)
+		case ast.ClassNode:
+			names = append(names, n.Ident)
+		case ast.FuncNode:
+			if !isDeclaration(n) {
+				continue
+			}
+			if err := hoistFunc(n, ev); err != nil {
+				return err
+			}
+		}
+		for _, ident := range names {
+			if err := ev.Declare(ident); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func hoistFunc(n ast.FuncNode, ev env.Environ[value.Value]) error {
+	fn, err := createFuncProto(n, ev)
+	if err != nil {
+		return err
+	}
+	err = ev.Define(fn.Ident, fn, false)
+	if errors.Is(err, env.ErrDefined) {
+		err = ev.Assign(fn.Ident, fn)
+	}
+	return err
+}
+
+func isDeclaration(n ast.Node) bool {
+	if x, ok := n.(ast.ExportNode); ok && !x.Default {
+		n = x.Node
+	}
+	fn, ok := n.(ast.FuncNode)
+	return ok && fn.Ident != ""
+}
+
+func varNames(n ast.Node) []string {
+	var list []string
+	switch n := n.(type) {
+	case evaluableNode:
+		list = varNames(n.Node)
+	case ast.VarDeclNode:
+		list = bindingNames(n.Ident)
+	case ast.BlockNode:
+		for _, n := range n.Nodes {
+			list = append(list, varNames(n)...)
+		}
+	case ast.IfNode:
+		list = append(varNames(n.Csq), varNames(n.Alt)...)
+	case ast.WhileNode:
+		list = varNames(n.Body)
+	case ast.DoNode:
+		list = varNames(n.Body)
+	case ast.ForNode:
+		list = append(varNames(n.Init), varNames(n.Body)...)
+	case ast.LoopNode:
+		switch it := n.Iter.(type) {
+		case ast.IterInNode:
+			list = varNames(it.Ident)
+		case ast.IterOfNode:
+			list = varNames(it.Ident)
+		}
+		list = append(list, varNames(n.Body)...)
+	case ast.TryNode:
+		list = append(varNames(n.Try), varNames(n.Catch)...)
+		list = append(list, varNames(n.Finally)...)
+	case ast.CatchNode:
+		list = varNames(n.Body)
+	case ast.SwitchNode:
+		for _, c := range n.Cases {
+			list = append(list, varNames(c)...)
+		}
+	case ast.CaseNode:
+		list = varNames(n.Body)
+	case ast.LabelNode:
+		list = varNames(n.Node)
+	case ast.ExportNode:
+		list = varNames(n.Node)
+	}
+	return list
+}
+
+func evalVarDecl(n ast.VarDeclNode, ev env.Environ[value.Value]) (value.Value, error) {
+	if n.Expr == nil {
+		for _, ident := range bindingNames(n.Ident) {
+			if err := declareVar(ident, ev); err != nil {
+				return nil, err
+			}
+		}
+		return value.Undefined(), nil
+	}
+	v, err := eval(n.Expr, ev)
+	if err != nil {
+		return nil, err
+	}
+	return v, assignVar(n.Ident, v, ev)
+}
+
+func assignVar(n ast.Node, v value.Value, ev env.Environ[value.Value]) error {
+	if id, ok := n.(ast.VarNode); ok {
+		if err := declareVar(id.Ident, ev); err != nil {
+			return err
+		}
+		return ev.Assign(id.Ident, v)
+	}
+	tmp := env.EnclosedEnv(ev)
+	if err := bindValue(n, v, tmp, false); err != nil {
+		return err
+	}
+	for _, ident := range bindingNames(n) {
+		v, err := tmp.Resolve(ident)
+		if err == nil {
+			err = assignVar(ast.CreateVar(ident), v, ev)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func declareVar(ident string, ev env.Environ[value.Value]) error {
+	_, err := ev.Resolve(ident)
+	if errors.Is(err, env.ErrNotDefined) {
+		err = ev.Define(ident, value.Undefined(), false)
+	}
+	return err
+}
@@ -0,0 +1,95 @@
+package eval
+
+import (
+	"fmt"
+
+	"github.com/midbel/enjoy/env"
+	"github.com/midbel/enjoy/value"
+)
+
+type objectIterator struct {
+	this value.Value
+	next value.Value
+	env.Environ[value.Value]
+}
+
+type iterCloser interface {
+	Close() error
+}
+
+func closeIterator(it value.Iterator) error {
+	c, ok := it.(iterCloser)
+	if !ok {
+		return nil
+	}
+	return c.Close()
+}
+
+func getIterator(v value.Value, ev env.Environ[value.Value]) (value.Iterator, error) {
+	if i, ok := v.(value.Iterable); ok {
+		return i.Iterate(), nil
+	}
+	if fn := getSymbol(v, value.SymbolIterator); !isNullish(fn) {
+		if !isCallable(fn) {
+			return nil, fmt.Errorf("Symbol.iterator is not a function: %w", value.ErrOperation)
+		}
+		it, err := callWith(fn, v, nil, ev)
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := it.(value.Iterable); ok {
+			return i.Iterate(), nil
+		}
+		v = it
+	}
+	if _, ok := v.(*value.Object); !ok {
+		return nil, value.ErrOperation
+	}
+	next, err := value.Get(v, "next")
+	if err != nil {
+		return nil, err
+	}
+	if !isCallable(next) {
+		return nil, value.ErrOperation
+	}
+	it := objectIterator{
+		this:    v,
+		next:    next,
+		Environ: ev,
+	}
+	return it, nil
+}
+
+func (i objectIterator) Next() (value.Value, bool, error) {
+	res, err := callWith(i.next, i.this, nil, i.Environ)
+	if err != nil {
+		return nil, false, err
+	}
+	if _, ok := res.(*value.Object); !ok {
+		return nil, false, value.ErrOperation
+	}
+	done, err := value.Get(res, "done")
+	if err != nil {
+		return nil, false, err
+	}
+	if done.True() {
+		return value.Undefined(), true, nil
+	}
+	v, err := value.Get(res, "value")
+	return v, false, err
+}
+
+func (i objectIterator) Close() error {
+	fn, err := value.Get(i.this, "return")
+	if err != nil || isNullish(fn) {
+		return err
+	}
+	if !isCallable(fn) {
+		return fmt.Errorf("return is not a function: %w", value.ErrOperation)
+	}
+	res, err := callWith(fn, i.this, nil, i.Environ)
+	if err == nil && !isObject(res) {
+		err = fmt.Errorf("iterator result is not an object: %w", value.ErrOperation)
+	}
+	return err
+}
@@ -0,0 +1,307 @@
+package eval
+
+import (
+	"sync"
+	"time"
+
+	"github.com/midbel/enjoy/env"
+	"github.com/midbel/enjoy/value"
+)
+
+const loopIdent = "#loop"
+
+type Clock interface {
+	Now() time.Time
+	After(time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+func (_ systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (_ systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type VirtualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewVirtualClock(now time.Time) *VirtualClock {
+	return &VirtualClock{
+		now: now,
+	}
+}
+
+func (c *VirtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func (c *VirtualClock) After(d time.Duration) <-chan time.Time {
+	c.Advance(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.Now()
+	return ch
+}
+
+type timer struct {
+	id       int
+	due      time.Time
+	interval time.Duration
+	repeat   bool
+	fn       func()
+}
+
+type Loop struct {
+	mu       sync.Mutex
+	jobs     []func()
+	timers   map[int]*timer
+	ids      int
+	pending  int
+	clock    Clock
+	err      error
+	wake     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+	proto    *value.Object
+	rejected []*promise
+	top      env.Environ[value.Value]
+}
+
+func NewLoop() *Loop {
+	return &Loop{
+		timers: make(map[int]*timer),
+		clock:  SystemClock(),
+		wake:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+}
+
+func LoopOf(ev env.Environ[value.Value]) *Loop {
+	v, err := ev.Resolve(loopIdent)
+	if err != nil {
+		return nil
+	}
+	l, _ := v.(*Loop)
+	return l
+}
+
+func runLoop(ev env.Environ[value.Value]) error {
+	if l := LoopOf(ev); l != nil {
+		return l.Run()
+	}
+	return nil
+}
+
+func (l *Loop) SetClock(c Clock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.clock = c
+}
+
+func (l *Loop) Enqueue(job func()) {
+	l.mu.Lock()
+	l.jobs = append(l.jobs, job)
+	l.mu.Unlock()
+	l.notify()
+}
+
+func (l *Loop) NewPromise() (value.Value, func(value.Value), func(value.Value)) {
+	var (
+		p       = createPromise(l)
+		release = l.hold()
+		once    sync.Once
+	)
+	settle := func(fn func(value.Value)) func(value.Value) {
+		return func(v value.Value) {
+			once.Do(func() {
+				l.Enqueue(func() {
+					fn(v)
+				})
+				release()
+			})
+		}
+	}
+	return p, settle(p.resolve), settle(p.reject)
+}
+
+func (l *Loop) Run() error {
+	for l.err == nil {
+		if job, ok := l.pop(); ok {
+			job()
+			continue
+		}
+		if err := l.unhandled(); err != nil {
+			l.fail(err)
+			continue
+		}
+		if t := l.next(); t != nil {
+			now := l.clock.Now()
+			if !t.due.After(now) {
+				l.fire(t)
+				continue
+			}
+			select {
+			case <-l.wake:
+			case <-l.clock.After(t.due.Sub(now)):
+			}
+			continue
+		}
+		if l.idle() {
+			break
+		}
+		<-l.wake
+	}
+	err := l.err
+	l.err = nil
+	return err
+}
+
+func (l *Loop) Close() {
+	l.once.Do(func() {
+		close(l.done)
+	})
+}
+
+func (l *Loop) track(p *promise) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rejected = append(l.rejected, p)
+}
+
+// unhandled reports the first promise rejected since the last call that still
+// has no handler once the pending jobs have run.
+func (l *Loop) unhandled() error {
+	l.mu.Lock()
+	list := l.rejected
+	l.rejected = nil
+	l.mu.Unlock()
+	for _, p := range list {
+		if !p.handled {
+			return ThrowError{Value: p.result}
+		}
+	}
+	return nil
+}
+
+func (l *Loop) fail(err error) {
+	if l.err == nil {
+		l.err = err
+	}
+}
+
+func (l *Loop) schedule(fn func(), delay time.Duration, repeat bool) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if delay < 0 {
+		delay = 0
+	}
+	l.ids++
+	l.timers[l.ids] = &timer{
+		id:       l.ids,
+		due:      l.clock.Now().Add(delay),
+		interval: delay,
+		repeat:   repeat,
+		fn:       fn,
+	}
+	return l.ids
+}
+
+func (l *Loop) cancel(id int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.timers, id)
+}
+
+func (l *Loop) next() *timer {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var next *timer
+	for _, t := range l.timers {
+		if next == nil || t.due.Before(next.due) || (t.due.Equal(next.due) && t.id < next.id) {
+			next = t
+		}
+	}
+	return next
+}
+
+func (l *Loop) fire(t *timer) {
+	l.mu.Lock()
+	if t.repeat {
+		t.due = t.due.Add(t.interval)
+		if t.interval == 0 {
+			t.due = l.clock.Now()
+		}
+	} else {
+		delete(l.timers, t.id)
+	}
+	l.mu.Unlock()
+	t.fn()
+}
+
+func (l *Loop) hold() func() {
+	l.mu.Lock()
+	l.pending++
+	l.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.pending--
+			l.mu.Unlock()
+			l.notify()
+		})
+	}
+}
+
+func (l *Loop) pop() (func(), bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.jobs) == 0 {
+		return nil, false
+	}
+	job := l.jobs[0]
+	l.jobs = l.jobs[1:]
+	return job, true
+}
+
+func (l *Loop) idle() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.jobs) == 0 && len(l.timers) == 0 && l.pending == 0
+}
+
+func (l *Loop) notify() {
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (_ *Loop) True() bool {
+	return true
+}
+
+func (_ *Loop) Type() string {
+	return "object"
+}
+
+func (_ *Loop) String() string {
+	return "[object Loop]"
+}
@@ -0,0 +1,397 @@
+package eval
+
+import (
+	"fmt"
+
+	"github.com/midbel/enjoy/builtins"
+	"github.com/midbel/enjoy/value"
+)
+
+type promiseState int
+
+const (
+	promisePending promiseState = iota
+	promiseFulfilled
+	promiseRejected
+)
+
+type promise struct {
+	loop      *Loop
+	state     promiseState
+	locked    bool
+	handled   bool
+	result    value.Value
+	reactions []func()
+}
+
+func createPromise(loop *Loop) *promise {
+	return &promise{
+		loop:   loop,
+		result: value.Undefined(),
+	}
+}
+
+func promiseResolve(loop *Loop, v value.Value) *promise {
+	if p, ok := v.(*promise); ok {
+		return p
+	}
+	p := createPromise(loop)
+	p.resolve(v)
+	return p
+}
+
+func (p *promise) Get(prop string) (value.Value, error) {
+	var fn value.BuiltinFunc
+	switch prop {
+	case "then":
+		fn = func(args ...value.Value) (value.Value, error) {
+			return p.then(argAt(args, 0), argAt(args, 1)), nil
+		}
+	case "catch":
+		fn = func(args ...value.Value) (value.Value, error) {
+			return p.then(value.Undefined(), argAt(args, 0)), nil
+		}
+	case "finally":
+		fn = func(args ...value.Value) (value.Value, error) {
+			return p.finally(argAt(args, 0)), nil
+		}
+	default:
+		return value.Undefined(), nil
+	}
+	return value.CreateBuiltin(prop, fn), nil
+}
+
+func (p *promise) Prototype() *value.Object {
+	return p.loop.proto
+}
+
+func (_ *promise) True() bool {
+	return true
+}
+
+func (_ *promise) Type() string {
+	return "object"
+}
+
+func (p *promise) String() string {
+	switch p.state {
+	case promiseFulfilled:
+		return fmt.Sprintf("Promise { %s }", p.result)
+	case promiseRejected:
+		return fmt.Sprintf("Promise { <rejected> %s }", describeError(p.result))
+	default:
+		return "Promise { <pending> }"
+	}
+}
+
+func (p *promise) resolve(v value.Value) {
+	if p.locked || p.state != promisePending {
+		return
+	}
+	if v == nil {
+		v = value.Undefined()
+	}
+	if v == value.Value(p) {
+		p.reject(builtins.CreateError(p.loop.top, "TypeError", "promise resolved with itself"))
+		return
+	}
+	if q, ok := v.(*promise); ok {
+		p.locked = true
+		q.subscribe(p.settle(promiseFulfilled), p.settle(promiseRejected))
+		return
+	}
+	if _, ok := v.(*value.Object); ok {
+		then, err := value.Get(v, "then")
+		if err != nil {
+			p.reject(thrownValue(err, p.loop.top))
+			return
+		}
+		if isCallable(then) {
+			p.locked = true
+			p.loop.Enqueue(func() {
+				resolve, reject := p.resolvers(true)
+				if _, err := callWith(then, v, []value.Value{resolve, reject}, nil); err != nil {
+					reject.Apply([]value.Value{thrownValue(err, p.loop.top)})
+				}
+			})
+			return
+		}
+	}
+	p.fulfill(v)
+}
+
+func (p *promise) fulfill(v value.Value) {
+	p.settle(promiseFulfilled)(v)
+}
+
+func (p *promise) reject(v value.Value) {
+	if p.locked {
+		return
+	}
+	p.settle(promiseRejected)(v)
+}
+
+func (p *promise) settle(state promiseState) func(value.Value) {
+	return func(v value.Value) {
+		if p.state != promisePending {
+			return
+		}
+		p.state = state
+		p.result = v
+		if state == promiseRejected && !p.handled {
+			p.loop.track(p)
+		}
+		for _, r := range p.reactions {
+			p.loop.Enqueue(r)
+		}
+		p.reactions = nil
+	}
+}
+
+func (p *promise) resolvers(locked bool) (value.Builtin, value.Builtin) {
+	var done bool
+	once := func(fn func(value.Value)) value.BuiltinFunc {
+		return func(args ...value.Value) (value.Value, error) {
+			if !done {
+				done = true
+				fn(argAt(args, 0))
+			}
+			return value.Undefined(), nil
+		}
+	}
+	resolve, reject := p.resolve, p.reject
+	if locked {
+		resolve = func(v value.Value) {
+			p.locked = false
+			p.resolve(v)
+		}
+		reject = func(v value.Value) {
+			p.locked = false
+			p.reject(v)
+		}
+	}
+	return value.CreateBuiltin("resolve", once(resolve)), value.CreateBuiltin("reject", once(reject))
+}
+
+func (p *promise) subscribe(onFulfilled, onRejected func(value.Value)) {
+	p.handled = true
+	react := func() {
+		if p.state == promiseFulfilled {
+			onFulfilled(p.result)
+		} else {
+			onRejected(p.result)
+		}
+	}
+	if p.state == promisePending {
+		p.reactions = append(p.reactions, react)
+		return
+	}
+	p.loop.Enqueue(react)
+}
+
+func (p *promise) then(onFulfilled, onRejected value.Value) *promise {
+	next := createPromise(p.loop)
+	handle := func(fn value.Value, settle func(value.Value)) func(value.Value) {
+		return func(v value.Value) {
+			if !isCallable(fn) {
+				settle(v)
+				return
+			}
+			res, err := callValue(fn, []value.Value{v}, nil)
+			if err != nil {
+				next.reject(thrownValue(err, p.loop.top))
+				return
+			}
+			next.resolve(res)
+		}
+	}
+	p.subscribe(handle(onFulfilled, next.resolve), handle(onRejected, next.reject))
+	return next
+}
+
+func (p *promise) finally(fn value.Value) *promise {
+	if !isCallable(fn) {
+		return p.then(value.Undefined(), value.Undefined())
+	}
+	next := createPromise(p.loop)
+	handle := func(settle func(value.Value)) func(value.Value) {
+		return func(v value.Value) {
+			res, err := callValue(fn, nil, nil)
+			if err != nil {
+				next.reject(thrownValue(err, p.loop.top))
+				return
+			}
+			promiseResolve(p.loop, res).subscribe(func(_ value.Value) {
+				settle(v)
+			}, next.reject)
+		}
+	}
+	p.subscribe(handle(next.resolve), handle(next.reject))
+	return next
+}
+
+func argAt(args []value.Value, i int) value.Value {
+	if i < len(args) {
+		return args[i]
+	}
+	return value.Undefined()
+}
+
+func promiseCtor(loop *Loop) value.Value {
+	ctor := func(args ...value.Value) (value.Value, error) {
+		exec := argAt(args, 0)
+		if !isCallable(exec) {
+			return nil, fmt.Errorf("promise resolver %s is not a function: %w", exec, value.ErrOperation)
+		}
+		p := createPromise(loop)
+		resolve, reject := p.resolvers(false)
+		if _, err := callValue(exec, []value.Value{resolve, reject}, nil); err != nil {
+			reject.Apply([]value.Value{thrownValue(err, p.loop.top)})
+		}
+		return p, nil
+	}
+	loop.proto = value.CreateObject(nil).(*value.Object)
+	b := value.CreateConstructor("Promise", ctor, loop.proto)
+
+	statics := map[string]func(*Loop, value.Value) (value.Value, error){
+		"resolve": func(loop *Loop, v value.Value) (value.Value, error) {
+			return promiseResolve(loop, v), nil
+		},
+		"reject": func(loop *Loop, v value.Value) (value.Value, error) {
+			p := createPromise(loop)
+			p.reject(v)
+			return p, nil
+		},
+		"all":        promiseAll,
+		"allSettled": promiseAllSettled,
+		"race":       promiseRace,
+		"any":        promiseAny,
+	}
+	for name, fn := range statics {
+		fn := fn
+		call := func(args ...value.Value) (value.Value, error) {
+			return fn(loop, argAt(args, 0))
+		}
+		b.Set(name, value.CreateBuiltin(name, call))
+	}
+	return b
+}
+
+func promiseList(loop *Loop, v value.Value) ([]*promise, error) {
+	it, err := getIterator(v, nil)
+	if err != nil {
+		return nil, err
+	}
+	var list []*promise
+	for {
+		v, done, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			break
+		}
+		list = append(list, promiseResolve(loop, v))
+	}
+	return list, nil
+}
+
+func promiseAll(loop *Loop, v value.Value) (value.Value, error) {
+	list, err := promiseList(loop, v)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		next    = createPromise(loop)
+		results = make([]value.Value, len(list))
+		remain  = len(list)
+	)
+	if remain == 0 {
+		next.resolve(value.CreateArray(results))
+	}
+	for i, p := range list {
+		i := i
+		fulfill := func(v value.Value) {
+			results[i] = v
+			if remain--; remain == 0 {
+				next.resolve(value.CreateArray(results))
+			}
+		}
+		p.subscribe(fulfill, next.reject)
+	}
+	return next, nil
+}
+
+func promiseAllSettled(loop *Loop, v value.Value) (value.Value, error) {
+	list, err := promiseList(loop, v)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		next    = createPromise(loop)
+		results = make([]value.Value, len(list))
+		remain  = len(list)
+	)
+	if remain == 0 {
+		next.resolve(value.CreateArray(results))
+	}
+	for i, p := range list {
+		i := i
+		settle := func(status, key string) func(value.Value) {
+			return func(v value.Value) {
+				obj := value.CreateObject(nil).(*value.Object)
+				obj.Set("status", value.CreateString(status))
+				obj.Set(key, v)
+				results[i] = obj
+				if remain--; remain == 0 {
+					next.resolve(value.CreateArray(results))
+				}
+			}
+		}
+		p.subscribe(settle("fulfilled", "value"), settle("rejected", "reason"))
+	}
+	return next, nil
+}
+
+func promiseRace(loop *Loop, v value.Value) (value.Value, error) {
+	list, err := promiseList(loop, v)
+	if err != nil {
+		return nil, err
+	}
+	next := createPromise(loop)
+	for _, p := range list {
+		p.subscribe(next.resolve, next.reject)
+	}
+	return next, nil
+}
+
+func promiseAny(loop *Loop, v value.Value) (value.Value, error) {
+	list, err := promiseList(loop, v)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		next   = createPromise(loop)
+		errs   = make([]value.Value, len(list))
+		remain = len(list)
+	)
+	reject := func() {
+		exc := builtins.CreateError(loop.top, "AggregateError", "All promises were rejected")
+		value.Set(exc, "errors", value.CreateArray(errs))
+		next.reject(exc)
+	}
+	if remain == 0 {
+		reject()
+	}
+	for i, p := range list {
+		i := i
+		rejected := func(v value.Value) {
+			errs[i] = v
+			if remain--; remain == 0 {
+				reject()
+			}
+		}
+		p.subscribe(next.resolve, rejected)
+	}
+	return next, nil
+}
@@ -0,0 +1,157 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/midbel/enjoy/value"
+)
+
+type Source struct {
+	Name    string
+	Code    []byte
+	Exports map[string]value.Value
+}
+
+type Resolver interface {
+	Resolve(spec, base string) (string, error)
+	Load(name string) (Source, error)
+}
+
+type fileResolver struct{}
+
+func OSResolver() Resolver {
+	return fileResolver{}
+}
+
+func (_ fileResolver) Resolve(spec, base string) (string, error) {
+	if filepath.IsAbs(spec) {
+		return filepath.Clean(spec), nil
+	}
+	if !isRelative(spec) {
+		return "", fmt.Errorf("%s: bare specifier not supported: %w", spec, ErrModule)
+	}
+	dir := "."
+	if base != "" {
+		dir = filepath.Dir(base)
+	}
+	return filepath.Abs(filepath.Join(dir, spec))
+}
+
+func (_ fileResolver) Load(name string) (Source, error) {
+	code, err := os.ReadFile(name)
+	if err != nil {
+		return Source{}, fmt.Errorf("%s: %w", name, ErrModule)
+	}
+	src := Source{
+		Name: name,
+		Code: code,
+	}
+	return src, nil
+}
+
+type fsResolver struct {
+	fsys fs.FS
+}
+
+func FSResolver(fsys fs.FS) Resolver {
+	return fsResolver{
+		fsys: fsys,
+	}
+}
+
+func (r fsResolver) Resolve(spec, base string) (string, error) {
+	var name string
+	switch {
+	case strings.HasPrefix(spec, "/"):
+		name = path.Clean(spec[1:])
+	case isRelative(spec):
+		name = path.Join(path.Dir(base), spec)
+	default:
+		return "", fmt.Errorf("%s: bare specifier not supported: %w", spec, ErrModule)
+	}
+	if !fs.ValidPath(name) {
+		return "", fmt.Errorf("%s: invalid module path: %w", spec, ErrModule)
+	}
+	return name, nil
+}
+
+func (r fsResolver) Load(name string) (Source, error) {
+	code, err := fs.ReadFile(r.fsys, name)
+	if err != nil {
+		return Source{}, fmt.Errorf("%s: %w", name, ErrModule)
+	}
+	src := Source{
+		Name: name,
+		Code: code,
+	}
+	return src, nil
+}
+
+const stdPrefix = "std:"
+
+type stdResolver struct {
+	modules map[string]map[string]value.Value
+}
+
+func StdResolver(modules map[string]map[string]value.Value) Resolver {
+	return stdResolver{
+		modules: modules,
+	}
+}
+
+func (r stdResolver) Resolve(spec, _ string) (string, error) {
+	if _, ok := r.modules[strings.TrimPrefix(spec, stdPrefix)]; !ok || !strings.HasPrefix(spec, stdPrefix) {
+		return "", fmt.Errorf("%s: %w", spec, ErrModule)
+	}
+	return spec, nil
+}
+
+func (r stdResolver) Load(name string) (Source, error) {
+	exports, ok := r.modules[strings.TrimPrefix(name, stdPrefix)]
+	if !ok || !strings.HasPrefix(name, stdPrefix) {
+		return Source{}, fmt.Errorf("%s: %w", name, ErrModule)
+	}
+	src := Source{
+		Name:    name,
+		Exports: exports,
+	}
+	return src, nil
+}
+
+type chainResolver []Resolver
+
+func ChainResolver(list ...Resolver) Resolver {
+	return chainResolver(list)
+}
+
+func (c chainResolver) Resolve(spec, base string) (string, error) {
+	err := fmt.Errorf("%s: %w", spec, ErrModule)
+	for _, r := range c {
+		var name string
+		if name, err = r.Resolve(spec, base); err == nil {
+			return name, nil
+		}
+	}
+	return "", err
+}
+
+func (c chainResolver) Load(name string) (Source, error) {
+	err := fmt.Errorf("%s: %w", name, ErrModule)
+	for _, r := range c {
+		var src Source
+		if src, err = r.Load(name); err == nil || !errors.Is(err, ErrModule) {
+			return src, err
+		}
+	}
+	return Source{}, err
+}
+
+func isRelative(spec string) bool {
+	return strings.HasPrefix(spec, "./") || strings.HasPrefix(spec, "../")
+}
@@ -0,0 +1,133 @@
+package eval
+
+import (
+	"fmt"
+
+	"github.com/midbel/enjoy/ast"
+	"github.com/midbel/enjoy/env"
+	"github.com/midbel/enjoy/value"
+)
+
+func propKey(ident string, key ast.Node, ev env.Environ[value.Value]) (value.Value, error) {
+	if key == nil {
+		return value.CreateString(ident), nil
+	}
+	return eval(key, ev)
+}
+
+func keyName(key value.Value) string {
+	sym, ok := key.(*value.Symbol)
+	if !ok {
+		return key.String()
+	}
+	if desc := sym.Description(); !value.IsUndefined(desc) {
+		return fmt.Sprintf("[%s]", desc)
+	}
+	return ""
+}
+
+func defineKey(obj *value.Object, key value.Value, d value.Descriptor) error {
+	if sym, ok := key.(*value.Symbol); ok {
+		return obj.DefineSymbol(sym, d)
+	}
+	return obj.Define(key.String(), d)
+}
+
+func ownKey(obj *value.Object, key value.Value) (value.Descriptor, bool) {
+	if sym, ok := key.(*value.Symbol); ok {
+		return obj.GetOwnSymbol(sym)
+	}
+	return obj.GetOwn(key.String())
+}
+
+func getSymbol(v value.Value, sym *value.Symbol) value.Value {
+	var (
+		res value.Value
+		err error
+	)
+	switch x := v.(type) {
+	case *value.Object:
+		res, err = x.GetSymbol(sym)
+	case *class:
+		res, err = x.Props.GetSymbol(sym)
+	case value.Func:
+		if x.Props != nil {
+			res, err = x.Props.GetSymbol(sym)
+		}
+	case value.Iterable:
+		if sym == value.SymbolIterator {
+			res = iteratorFunc(v)
+		}
+	}
+	if err != nil || res == nil {
+		return value.Undefined()
+	}
+	return res
+}
+
+// iteratorFunc returns the Symbol.iterator method of the builtin iterables.
+// Arrays and strings hand out a fresh iterator while iterators return
+// themselves.
+func iteratorFunc(v value.Value) value.Value {
+	call := func(_ ...value.Value) (value.Value, error) {
+		switch x := v.(type) {
+		case *value.Array:
+			return value.CreateIterator("Array Iterator", x.Iterate()), nil
+		case value.Str:
+			return value.CreateIterator("String Iterator", x.Iterate()), nil
+		default:
+			return v, nil
+		}
+	}
+	return value.CreateBuiltin("[Symbol.iterator]", call)
+}
+
+func toPrimitive(v value.Value, hint string, ev env.Environ[value.Value]) (value.Value, error) {
+	if !isObject(v) {
+		return v, nil
+	}
+	fn := getSymbol(v, value.SymbolToPrimitive)
+	if isNullish(fn) {
+		return ordinaryToPrimitive(v, hint, ev)
+	}
+	if !isCallable(fn) {
+		return nil, fmt.Errorf("Symbol.toPrimitive is not a function: %w", value.ErrOperation)
+	}
+	res, err := callWith(fn, v, []value.Value{value.CreateString(hint)}, ev)
+	if err != nil {
+		return nil, err
+	}
+	if isObject(res) {
+		return nil, fmt.Errorf("cannot convert object to primitive value: %w", value.ErrOperation)
+	}
+	return res, nil
+}
+
+func ordinaryToPrimitive(v value.Value, hint string, ev env.Environ[value.Value]) (value.Value, error) {
+	if _, ok := v.(*value.Object); !ok {
+		return v, nil
+	}
+	methods := []string{"valueOf", "toString"}
+	if hint == "string" {
+		methods = []string{"toString", "valueOf"}
+	}
+	var found bool
+	for _, name := range methods {
+		fn, err := value.Get(v, name)
+		if err != nil || !isCallable(fn) {
+			continue
+		}
+		found = true
+		res, err := callWith(fn, v, nil, ev)
+		if err != nil {
+			return nil, err
+		}
+		if !isObject(res) {
+			return res, nil
+		}
+	}
+	if found {
+		return nil, fmt.Errorf("cannot convert object to primitive value: %w", value.ErrOperation)
+	}
+	return v, nil
+}
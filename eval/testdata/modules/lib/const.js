@@ -0,0 +1 @@
+export const PI = 3
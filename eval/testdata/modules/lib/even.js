@@ -0,0 +1,2 @@
+import { odd } from "./odd.js"
+export function even(n) { if (n == 0) { return true }; return odd(n - 1) }
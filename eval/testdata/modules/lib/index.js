@@ -0,0 +1,5 @@
+export * from "./math.js"
+export * as all from "./math.js"
+export { PI } from "./const.js"
+const twice = (x) => x * 2
+export { twice }
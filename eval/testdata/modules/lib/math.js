@@ -0,0 +1,5 @@
+export let counter = 0
+export function add(a, b) { return a + b }
+function incr() { counter = counter + 1 }
+export { incr }
+export default "math"
@@ -0,0 +1,2 @@
+import { even } from "./even.js"
+export function odd(n) { if (n == 0) { return false }; return even(n - 1) }
@@ -0,0 +1 @@
+export class Point { constructor(x) { this.x = x } }
@@ -0,0 +1,11 @@
+import def, { add, counter as cnt, incr } from "./lib/math.js"
+import * as ns from "./lib/math.js"
+import { even } from "./lib/even.js"
+import answer from "./lib/answer.js"
+import { Point } from "./lib/point.js"
+import { PI, twice, all } from "./lib/index.js"
+const out = [def, add(1, 2), cnt]
+incr()
+out.push(cnt, ns.counter, even(4), even(3), PI, twice(3), all.add(2, 2), answer, new Point(7).x)
+try { cnt = 5 } catch (e) { out.push(e.name) }
+out
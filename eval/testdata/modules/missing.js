@@ -0,0 +1 @@
+import {nope} from "./lib/math.js"
@@ -0,0 +1,82 @@
+package eval
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/midbel/enjoy/value"
+)
+
+func setTimer(loop *Loop, repeat bool) value.Value {
+	name := "setTimeout"
+	if repeat {
+		name = "setInterval"
+	}
+	fn := func(args ...value.Value) (value.Value, error) {
+		call := argAt(args, 0)
+		if !isCallable(call) {
+			return nil, fmt.Errorf("callback %s is not a function: %w", call, value.ErrArgument)
+		}
+		delay, err := toMillis(argAt(args, 1))
+		if err != nil {
+			return nil, err
+		}
+		var rest []value.Value
+		if len(args) > 2 {
+			rest = args[2:]
+		}
+		job := func() {
+			if _, err := callValue(call, rest, nil); err != nil {
+				loop.fail(err)
+			}
+		}
+		id := loop.schedule(job, delay, repeat)
+		return value.CreateFloat(float64(id)), nil
+	}
+	return value.CreateBuiltin(name, fn)
+}
+
+func clearTimer(loop *Loop) value.Value {
+	fn := func(args ...value.Value) (value.Value, error) {
+		id, err := value.Coerce(argAt(args, 0))
+		if err != nil {
+			return nil, err
+		}
+		if f, ok := id.(value.Float); ok {
+			loop.cancel(int(f.Native()))
+		}
+		return value.Undefined(), nil
+	}
+	return value.CreateBuiltin("clearTimeout", fn)
+}
+
+func queueMicrotask(loop *Loop) value.Value {
+	fn := func(args ...value.Value) (value.Value, error) {
+		call := argAt(args, 0)
+		if !isCallable(call) {
+			return nil, fmt.Errorf("callback %s is not a function: %w", call, value.ErrArgument)
+		}
+		loop.Enqueue(func() {
+			if _, err := callValue(call, nil, nil); err != nil {
+				loop.fail(err)
+			}
+		})
+		return value.Undefined(), nil
+	}
+	return value.CreateBuiltin("queueMicrotask", fn)
+}
+
+func toMillis(v value.Value) (time.Duration, error) {
+	if value.IsUndefined(v) {
+		return 0, nil
+	}
+	v, err := value.Coerce(v)
+	if err != nil {
+		return 0, err
+	}
+	f, ok := v.(value.Float)
+	if !ok {
+		return 0, nil
+	}
+	return time.Duration(f.Native() * float64(time.Millisecond)), nil
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"github.com/midbel/enjoy/ast"
+	"github.com/midbel/enjoy/token"
 )
 
 func makeLet(ident ast.Node) ast.LetNode {
@@ -16,6 +17,12 @@ func makeConst(ident ast.Node) ast.ConstNode {
 	}
 }
 
+func makeVarDecl(ident ast.Node) ast.VarDeclNode {
+	return ast.VarDeclNode{
+		Ident: ident,
+	}
+}
+
 func makeSpreadWithVar(ident string) ast.SpreadNode {
 	return makeSpreadFrom(ast.CreateVar(ident))
 }
@@ -46,9 +53,26 @@ func makeIterIn(id, it ast.Node) ast.IterInNode {
 	}
 }
 
-func makeCall(id ast.Node) ast.CallNode {
+func makeCall(id ast.Node, pos token.Position) ast.CallNode {
 	return ast.CallNode{
-		Ident: id,
+		Ident:    id,
+		Position: pos,
+	}
+}
+
+func makeOptional(n ast.Node) ast.Node {
+	switch x := n.(type) {
+	case ast.MemberNode:
+		x.Optional = true
+		return x
+	case ast.IndexNode:
+		x.Optional = true
+		return x
+	case ast.CallNode:
+		x.Optional = true
+		return x
+	default:
+		return n
 	}
 }
 
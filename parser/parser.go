@@ -34,6 +34,7 @@ type Parser struct {
 	peek token.Token
 
 	allowDestructAssign int
+	noIn                bool
 }
 
 func NewParser(r io.Reader) *Parser {
@@ -60,6 +61,8 @@ func NewParser(r io.Reader) *Parser {
 	p.registerPrefix(token.Lbrace, p.parseBrace)
 	p.registerPrefix(token.Lsquare, p.parseSquare)
 	p.registerPrefix(token.Spread, p.parseSpread)
+	p.registerPrefix(token.Div, p.parseRegex)
+	p.registerPrefix(token.DivAssign, p.parseRegex)
 
 	p.registerInfix(token.Eq, p.parseBinary)
 	p.registerInfix(token.Seq, p.parseBinary)
@@ -80,6 +83,7 @@ func NewParser(r io.Reader) *Parser {
 	p.registerInfix(token.Or, p.parseBinary)
 	p.registerInfix(token.Lshift, p.parseBinary)
 	p.registerInfix(token.Rshift, p.parseBinary)
+	p.registerInfix(token.Urshift, p.parseBinary)
 	p.registerInfix(token.Band, p.parseBinary)
 	p.registerInfix(token.Bor, p.parseBinary)
 	p.registerInfix(token.Bxor, p.parseBinary)
@@ -95,19 +99,23 @@ func NewParser(r io.Reader) *Parser {
 	p.registerInfix(token.OrAssign, p.parseAssign)
 	p.registerInfix(token.LshiftAssign, p.parseAssign)
 	p.registerInfix(token.RshiftAssign, p.parseAssign)
+	p.registerInfix(token.UrshiftAssign, p.parseAssign)
 	p.registerInfix(token.BandAssign, p.parseAssign)
 	p.registerInfix(token.BorAssign, p.parseAssign)
 	p.registerInfix(token.BxorAssign, p.parseAssign)
+	p.registerInfix(token.Increment, p.parsePostfix)
+	p.registerInfix(token.Decrement, p.parsePostfix)
 	p.registerInfix(token.Question, p.parseTernary)
 	p.registerInfix(token.Lparen, p.parseCall)
 	p.registerInfix(token.Lsquare, p.parseIndex)
 	p.registerInfix(token.Arrow, p.parseArrow)
 	p.registerInfix(token.Dot, p.parseMember)
-	p.registerInfix(token.Optional, p.parseMember)
+	p.registerInfix(token.Optional, p.parseOptional)
 	p.registerInfix(token.Keyword, p.parseOperatorKeyword)
 
 	p.registerKeyword("let", p.parseLet)
 	p.registerKeyword("const", p.parseConst)
+	p.registerKeyword("var", p.parseVar)
 	p.registerKeyword("if", p.parseIf)
 	p.registerKeyword("else", p.parseElse)
 	p.registerKeyword("switch", p.parseSwitch)
@@ -123,11 +131,18 @@ func NewParser(r io.Reader) *Parser {
 	p.registerKeyword("throw", p.parseThrow)
 	p.registerKeyword("function", p.parseFunction)
 	p.registerKeyword("return", p.parseReturn)
+	p.registerKeyword("yield", p.parseYield)
+	p.registerKeyword("async", p.parseAsync)
+	p.registerKeyword("await", p.parseAwait)
 	p.registerKeyword("null", p.parseNull)
 	p.registerKeyword("undefined", p.parseUndefined)
 	p.registerKeyword("typeof", p.parseTypeOf)
 	p.registerKeyword("export", p.parseExport)
 	p.registerKeyword("import", p.parseImport)
+	p.registerKeyword("new", p.parseNew)
+	p.registerKeyword("this", p.parseThis)
+	p.registerKeyword("super", p.parseSuper)
+	p.registerKeyword("class", p.parseClass)
 
 	p.next()
 	p.next()
@@ -146,7 +161,7 @@ func (p *Parser) parse() (ast.Node, error) {
 	var b ast.BlockNode
 	for !p.done() {
 		p.skip(token.Comment)
-		n, err := p.parseNode(powLowest)
+		n, err := p.parseStatement()
 		if err != nil {
 			return nil, err
 		}
@@ -162,6 +177,27 @@ func (p *Parser) parse() (ast.Node, error) {
 	return b, nil
 }
 
+func (p *Parser) parseStatement() (ast.Node, error) {
+	if p.is(token.Ident) && p.peek.Type == token.Colon {
+		return p.parseLabel()
+	}
+	return p.parseNode(powLowest)
+}
+
+func (p *Parser) parseLabel() (ast.Node, error) {
+	ident := p.curr.Literal
+	p.next()
+	if err := p.expect(token.Colon); err != nil {
+		return nil, err
+	}
+	p.skip(token.EOL)
+	node, err := p.parseStatement()
+	if err != nil {
+		return nil, err
+	}
+	return ast.Label(ident, node), nil
+}
+
 func (p *Parser) parseNode(pow int) (ast.Node, error) {
 	fn, ok := p.prefix[p.curr.Type]
 	if !ok {
@@ -171,6 +207,11 @@ func (p *Parser) parseNode(pow int) (ast.Node, error) {
 	if err != nil {
 		return nil, err
 	}
+	return p.parseInfix(left, pow)
+}
+
+func (p *Parser) parseInfix(left ast.Node, pow int) (ast.Node, error) {
+	var err error
 	for !p.done() && !p.eol() && pow < p.power() {
 		fn, ok := p.infix[p.curr.Type]
 		if !ok {
@@ -239,6 +280,16 @@ func (p *Parser) parseLet() (ast.Node, error) {
 	return bind, err
 }
 
+func (p *Parser) parseVar() (ast.Node, error) {
+	node, done, err := p.parseBinding(true)
+	if err != nil || done {
+		return makeVarDecl(node), err
+	}
+	bind := makeVarDecl(node)
+	bind.Expr, err = p.parseNode(powLowest)
+	return bind, err
+}
+
 func (p *Parser) parseConst() (ast.Node, error) {
 	node, _, err := p.parseBinding(false)
 	if err != nil {
@@ -260,28 +311,13 @@ func (p *Parser) parseObject() (ast.Node, error) {
 	if err := p.expect(token.Lbrace); err != nil {
 		return nil, err
 	}
-	list := make(map[string]ast.Node)
+	var list []ast.Node
 	for !p.done() && !p.is(token.Rbrace) {
-		if !p.is(token.Ident) && !p.is(token.String) && !p.is(token.Number) && !p.is(token.Boolean) {
-			return nil, p.unexpected()
-		}
-		ident := p.curr.Literal
-		p.next()
-		if p.is(token.Comma) || p.is(token.Rbrace) {
-			list[ident] = ast.CreateVar(ident)
-			if p.is(token.Comma) {
-				p.next()
-			}
-			continue
-		}
-		if err := p.expect(token.Colon); err != nil {
-			return nil, err
-		}
-		node, err := p.parseNode(powComma)
+		node, err := p.parseObjectMember()
 		if err != nil {
 			return nil, err
 		}
-		list[ident] = node
+		list = append(list, node)
 		switch {
 		case p.is(token.Comma):
 			p.next()
@@ -293,58 +329,149 @@ func (p *Parser) parseObject() (ast.Node, error) {
 	return ast.Object(list), p.expect(token.Rbrace)
 }
 
+func (p *Parser) parseObjectMember() (ast.Node, error) {
+	if p.is(token.Spread) {
+		p.next()
+		node, err := p.parseNode(powComma)
+		return makeSpreadFrom(node), err
+	}
+	if p.isAccessor() {
+		kind := p.curr.Literal
+		p.next()
+		var node ast.AccessorNode
+		if err := p.parseAccessorKey(&node); err != nil {
+			return nil, err
+		}
+		return node, p.parseAccessor(kind, &node)
+	}
+	var async bool
+	if p.is(token.Keyword) && p.curr.Literal == "async" && !p.isKeyEnd(p.peek.Type) {
+		async = true
+		p.next()
+	}
+	var generator bool
+	if p.is(token.Mul) {
+		generator = true
+		p.next()
+	}
+	var (
+		node      ast.PropNode
+		ident     = p.curr.Literal
+		shorthand = p.is(token.Ident)
+		err       error
+	)
+	switch {
+	case p.is(token.Lsquare):
+		node.Computed = true
+		if node.Key, err = p.parseComputedKey(); err != nil {
+			return nil, err
+		}
+		ident = ""
+		shorthand = false
+	case p.is(token.Ident), p.is(token.Keyword), p.is(token.String), p.is(token.Number), p.is(token.Boolean):
+		node.Key = ast.CreateValue(ident)
+		p.next()
+	default:
+		return nil, p.unexpected()
+	}
+	switch {
+	case p.is(token.Lparen):
+		fn := ast.FuncNode{
+			Ident:     ident,
+			Generator: generator,
+			Async:     async,
+		}
+		if fn.Args, err = p.parseArgs(); err != nil {
+			return nil, err
+		}
+		if fn.Body, err = p.parseBody(); err != nil {
+			return nil, err
+		}
+		node.Value = ast.MethodNode{
+			Ident: ident,
+			Func:  fn,
+		}
+	case async || generator:
+		return nil, p.unexpected()
+	case p.is(token.Colon):
+		p.next()
+		if node.Value, err = p.parseNode(powComma); err != nil {
+			return nil, err
+		}
+	case !shorthand:
+		return nil, p.unexpected()
+	case p.is(token.Assign):
+		p.next()
+		assign := makeAssignNode(ast.CreateVar(ident))
+		if assign.Expr, err = p.parseNode(powComma); err != nil {
+			return nil, err
+		}
+		node.Value = assign
+	default:
+		node.Value = ast.CreateVar(ident)
+	}
+	return node, nil
+}
+
+func (p *Parser) isKeyEnd(kind rune) bool {
+	switch kind {
+	case token.Lparen, token.Colon, token.Comma, token.Rbrace, token.Assign:
+		return true
+	default:
+		return false
+	}
+}
+
 func (p *Parser) parseObjectBinding() (ast.Node, error) {
 	if err := p.expect(token.Lbrace); err != nil {
 		return nil, err
 	}
-	list := make(map[string]ast.Node)
+	var list []ast.Node
 	for !p.done() && !p.is(token.Rbrace) {
 		if p.is(token.Spread) {
 			p.next()
 			if !p.is(token.Ident) {
 				return nil, p.unexpected()
 			}
-			list[p.curr.Literal] = makeSpreadWithVar(p.curr.Literal)
+			list = append(list, makeSpreadWithVar(p.curr.Literal))
 			p.next()
 			if !p.is(token.Rbrace) {
 				return nil, p.unexpected()
 			}
 			continue
 		}
-		if !p.is(token.Ident) && !p.is(token.String) && !p.is(token.Number) && !p.is(token.Boolean) {
-			return nil, p.unexpected()
-		}
 		var (
+			node  ast.PropNode
 			ident = p.curr.Literal
-			value ast.AssignNode
 			err   error
 		)
-		p.next()
-		if p.is(token.Colon) {
-			p.next()
-			switch {
-			case p.is(token.Lbrace):
-				value.Ident, err = p.parseObjectBinding()
-				if err != nil {
-					return nil, err
-				}
-			case p.is(token.Ident):
-				value.Ident = ast.CreateVar(p.curr.Literal)
-				p.next()
-			default:
-				return nil, p.unexpected()
+		switch {
+		case p.is(token.Lsquare):
+			node.Computed = true
+			if node.Key, err = p.parseComputedKey(); err != nil {
+				return nil, err
 			}
-		} else {
-			value.Ident = ast.CreateVar(ident)
+		case p.is(token.Ident), p.is(token.Keyword), p.is(token.String), p.is(token.Number), p.is(token.Boolean):
+			node.Key = ast.CreateValue(ident)
+			p.next()
+		default:
+			return nil, p.unexpected()
 		}
-		if p.is(token.Assign) {
+		switch {
+		case p.is(token.Colon):
 			p.next()
-			value.Expr, err = p.parseNode(powComma)
-			if err != nil {
+			if node.Value, err = p.parseBindingTarget(); err != nil {
 				return nil, err
 			}
+		case node.Computed:
+			return nil, p.unexpected()
+		default:
+			node.Value = ast.CreateVar(ident)
+		}
+		if node.Value, err = p.parseBindingDefault(node.Value); err != nil {
+			return nil, err
 		}
-		list[ident] = value
+		list = append(list, node)
 		switch {
 		case p.is(token.Comma):
 			p.next()
@@ -356,6 +483,53 @@ func (p *Parser) parseObjectBinding() (ast.Node, error) {
 	return ast.BindObject(list), p.expect(token.Rbrace)
 }
 
+func (p *Parser) parseBindingTarget() (ast.Node, error) {
+	switch {
+	case p.is(token.Ident):
+		defer p.next()
+		return ast.CreateVar(p.curr.Literal), nil
+	case p.is(token.Lbrace):
+		return p.parseObjectBinding()
+	case p.is(token.Lsquare):
+		return p.parseArrayBinding()
+	default:
+		return nil, p.unexpected()
+	}
+}
+
+func (p *Parser) parseBindingDefault(node ast.Node) (ast.Node, error) {
+	if !p.is(token.Assign) {
+		return node, nil
+	}
+	p.next()
+	var (
+		ass = makeAssignNode(node)
+		err error
+	)
+	ass.Expr, err = p.parseBindingExpr()
+	return ass, err
+}
+
+func (p *Parser) parseComputedKey() (ast.Node, error) {
+	if err := p.expect(token.Lsquare); err != nil {
+		return nil, err
+	}
+	key, err := p.parseBindingExpr()
+	if err != nil {
+		return nil, err
+	}
+	return key, p.expect(token.Rsquare)
+}
+
+func (p *Parser) parseBindingExpr() (ast.Node, error) {
+	allow := p.allowDestructAssign
+	p.resetDestructuring()
+	defer func() {
+		p.allowDestructAssign = allow
+	}()
+	return p.parseNode(powComma)
+}
+
 func (p *Parser) parseSquare() (ast.Node, error) {
 	if p.isDestructuringAllowed() {
 		return p.parseArrayBinding()
@@ -394,56 +568,38 @@ func (p *Parser) parseArrayBinding() (ast.Node, error) {
 	if err := p.expect(token.Lsquare); err != nil {
 		return nil, err
 	}
-	var (
-		list []ast.Node
-		err  error
-	)
+	var list []ast.Node
 	for !p.done() && !p.is(token.Rsquare) {
 		if p.is(token.Comma) {
 			p.next()
 			list = append(list, ast.Discard())
 			continue
 		}
-		var node ast.Node
-		switch {
-		case p.is(token.Comma):
-			node = ast.Discard()
-			p.next()
-		case p.is(token.Ident):
-			node = ast.CreateVar(p.curr.Literal)
-			p.next()
-		case p.is(token.Lbrace):
-			node, err = p.parseObjectBinding()
-		case p.is(token.Spread):
+		var (
+			node ast.Node
+			err  error
+		)
+		if p.is(token.Spread) {
 			p.next()
-			if p.is(token.Ident) {
-				node = makeSpreadWithVar(p.curr.Literal)
-				p.next()
-			} else {
-				node, err = p.parseArrayBinding()
-				if err == nil {
-					node = makeSpreadFrom(node)
-				}
+			if node, err = p.parseBindingTarget(); err != nil {
+				return nil, err
 			}
-		default:
-			return nil, p.unexpected()
+			list = append(list, makeSpreadFrom(node))
+			if !p.is(token.Rsquare) {
+				return nil, p.unexpected()
+			}
+			continue
 		}
-		if err != nil {
+		if node, err = p.parseBindingTarget(); err != nil {
 			return nil, err
 		}
-		if _, ok := node.(ast.SpreadNode); !ok && p.is(token.Assign) {
-			ass := makeAssignNode(node)
-			p.next()
-			ass.Expr, err = p.parseNode(powComma)
-			node = ass
+		if node, err = p.parseBindingDefault(node); err != nil {
+			return nil, err
 		}
 		list = append(list, node)
 		switch {
 		case p.is(token.Comma):
 			p.next()
-			if _, ok := node.(ast.SpreadNode); ok && p.is(token.Rsquare) {
-				return nil, p.unexpected()
-			}
 		case p.is(token.Rsquare):
 		default:
 			return nil, p.unexpected()
@@ -484,7 +640,7 @@ func (p *Parser) parseIn(left ast.Node) (ast.Node, error) {
 	node = ast.InNode{
 		Left: left,
 	}
-	node.Right, err = p.parseNode(powLowest)
+	node.Right, err = p.parseNode(powCompare)
 	return node, err
 }
 
@@ -499,7 +655,7 @@ func (p *Parser) parseInstanceOf(left ast.Node) (ast.Node, error) {
 	node = ast.InstanceOfNode{
 		Left: left,
 	}
-	node.Right, err = p.parseNode(powLowest)
+	node.Right, err = p.parseNode(powCompare)
 	return node, err
 }
 
@@ -705,7 +861,9 @@ func (p *Parser) parseImport() (ast.Node, error) {
 	case p.is(token.Ident):
 		ident := ast.CreateVar(p.curr.Literal)
 		if file, err := parseFrom(); err == nil {
-			return ast.Import(ident, file), nil
+			n := ast.Import(nil, file)
+			n.Default = ident
+			return n, nil
 		}
 		if err := p.expect(token.Comma); err != nil {
 			return nil, err
@@ -757,6 +915,7 @@ func (p *Parser) parseSwitch() (ast.Node, error) {
 	var (
 		node ast.SwitchNode
 		err  error
+		seen bool
 	)
 	node.Cdt, err = p.parseCondition()
 	if err != nil {
@@ -769,22 +928,24 @@ func (p *Parser) parseSwitch() (ast.Node, error) {
 		if !p.is(token.Keyword) {
 			return nil, p.unexpected()
 		}
-		if p.curr.Literal == "default" {
-			break
-		}
-		if p.curr.Literal != "case" {
+		var b ast.Node
+		switch p.curr.Literal {
+		case "case":
+			b, err = p.parseCase()
+		case "default":
+			if seen {
+				return nil, p.unexpected()
+			}
+			seen = true
+			b, err = p.parseDefault()
+		default:
 			return nil, p.unexpected()
 		}
-		b, err := p.parseCase()
 		if err != nil {
 			return nil, err
 		}
 		node.Cases = append(node.Cases, b)
 	}
-	if p.is(token.Keyword) && p.curr.Literal == "default" {
-		node.Default, err = p.parseDefault()
-		return node, err
-	}
 	return node, p.expect(token.Rbrace)
 }
 
@@ -793,17 +954,12 @@ func (p *Parser) parseDefault() (ast.Node, error) {
 	if err := p.expect(token.Colon); err != nil {
 		return nil, err
 	}
-	var nodes []ast.Node
-	p.skip(token.EOL)
-	for !p.done() && !p.is(token.Rbrace) {
-		n, err := p.parseNode(powLowest)
-		if err != nil {
-			return nil, err
-		}
-		p.skip(token.EOL)
-		nodes = append(nodes, n)
-	}
-	return blockOrNode(nodes), p.expect(token.Rbrace)
+	var (
+		clause ast.CaseNode
+		err    error
+	)
+	clause.Body, err = p.parseClause()
+	return clause, err
 }
 
 func (p *Parser) parseCase() (ast.Node, error) {
@@ -812,38 +968,104 @@ func (p *Parser) parseCase() (ast.Node, error) {
 		clause ast.CaseNode
 		err    error
 	)
-	clause.Predicate, err = p.parseNode(powAssign)
-	if err != nil {
-		return nil, err
+	if p.is(token.Ident) && p.peek.Type == token.Colon {
+		clause.Predicate = ast.CreateVar(p.curr.Literal)
+		p.next()
+	} else {
+		clause.Predicate, err = p.parseNode(powAssign)
+		if err != nil {
+			return nil, err
+		}
 	}
 	if err = p.expect(token.Colon); err != nil {
 		return nil, err
 	}
+	clause.Body, err = p.parseClause()
+	return clause, err
+}
+
+func (p *Parser) parseClause() (ast.Node, error) {
 	var nodes []ast.Node
 	p.skip(token.EOL)
 	for !p.done() && !p.is(token.Rbrace) {
 		if p.is(token.Keyword) && (p.curr.Literal == "case" || p.curr.Literal == "default") {
 			break
 		}
-		n, err := p.parseNode(powLowest)
+		n, err := p.parseStatement()
 		if err != nil {
 			return nil, err
 		}
 		p.skip(token.EOL)
 		nodes = append(nodes, n)
 	}
-	clause.Body = blockOrNode(nodes)
-	return clause, nil
+	return blockOrNode(nodes), nil
 }
 
-func (p *Parser) parseForeach() (ast.Node, bool, error) {
-	n, err := p.parseNode(powLowest)
-	if err == nil {
-		return n, false, err
+func (p *Parser) parseForeach() (ast.Node, error) {
+	var kw string
+	if p.is(token.Keyword) && (p.curr.Literal == "let" || p.curr.Literal == "const" || p.curr.Literal == "var") {
+		kw = p.curr.Literal
+		p.enableDestructuring()
+		p.next()
 	}
-	if !p.is(token.Assign) && !p.is(token.Keyword) {
-		return nil, false, err
+	p.noIn = true
+	n, err := p.parseNode(powAssign)
+	p.noIn = false
+	if kw != "" {
+		p.disableDestructuring()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.is(token.Keyword) && (p.curr.Literal == "of" || p.curr.Literal == "in") {
+		switch kw {
+		case "let":
+			n = makeLet(n)
+		case "const":
+			n = makeConst(n)
+		case "var":
+			n = makeVarDecl(n)
+		default:
+			if n, err = p.parseAssignPattern(n); err != nil {
+				return nil, err
+			}
+		}
+		return p.parseIter(n)
 	}
+	switch kw {
+	case "let":
+		bind := makeLet(n)
+		if p.is(token.EOL) {
+			return bind, nil
+		}
+		if err := p.expect(token.Assign); err != nil {
+			return nil, err
+		}
+		bind.Expr, err = p.parseNode(powLowest)
+		return bind, err
+	case "var":
+		bind := makeVarDecl(n)
+		if p.is(token.EOL) {
+			return bind, nil
+		}
+		if err := p.expect(token.Assign); err != nil {
+			return nil, err
+		}
+		bind.Expr, err = p.parseNode(powLowest)
+		return bind, err
+	case "const":
+		bind := makeConst(n)
+		if err := p.expect(token.Assign); err != nil {
+			return nil, err
+		}
+		bind.Expr, err = p.parseNode(powLowest)
+		return bind, err
+	default:
+		return p.parseInfix(n, powLowest)
+	}
+}
+
+func (p *Parser) parseIter(ident ast.Node) (ast.Node, error) {
 	var (
 		loop ast.LoopNode
 		kw   = p.curr.Literal
@@ -851,21 +1073,17 @@ func (p *Parser) parseForeach() (ast.Node, bool, error) {
 	p.next()
 	it, err := p.parseNode(powLowest)
 	if err != nil {
-		return nil, false, err
+		return nil, err
 	}
 	switch kw {
 	default:
-		return nil, false, p.unexpected()
+		return nil, p.unexpected()
 	case "of":
-		loop.Iter = makeIterOf(n, it)
+		loop.Iter = makeIterOf(ident, it)
 	case "in":
-		loop.Iter = makeIterIn(n, it)
-	}
-	if err := p.expect(token.Rparen); err != nil {
-		return nil, false, err
+		loop.Iter = makeIterIn(ident, it)
 	}
-	loop.Body, err = p.parseBody()
-	return loop, true, err
+	return loop, p.expect(token.Rparen)
 }
 
 func (p *Parser) parseFor() (ast.Node, error) {
@@ -879,10 +1097,14 @@ func (p *Parser) parseFor() (ast.Node, error) {
 		return nil, err
 	}
 	if !p.is(token.EOL) {
-		n, done, err := p.parseForeach()
-		if err != nil || done {
+		n, err := p.parseForeach()
+		if err != nil {
+			return nil, err
+		}
+		if loop, ok := n.(ast.LoopNode); ok {
 			p.scan.ToggleKeepEOL()
-			return n, err
+			loop.Body, err = p.parseBody()
+			return loop, err
 		}
 		node.Init = n
 	}
@@ -992,9 +1214,13 @@ func (p *Parser) parseCatch() (ast.Node, error) {
 		catch ast.CatchNode
 		err   error
 	)
-	catch.Ident, err = p.parseCondition()
-	if err != nil {
-		return nil, err
+	if p.is(token.Lparen) {
+		p.enableDestructuring()
+		catch.Ident, err = p.parseCondition()
+		p.disableDestructuring()
+		if err != nil {
+			return nil, err
+		}
 	}
 	catch.Body, err = p.parseBody()
 	return catch, err
@@ -1021,6 +1247,10 @@ func (p *Parser) parseFunction() (ast.Node, error) {
 		fn  ast.FuncNode
 		err error
 	)
+	if p.is(token.Mul) {
+		fn.Generator = true
+		p.next()
+	}
 	if p.is(token.Ident) {
 		fn.Ident = p.curr.Literal
 		p.next()
@@ -1032,6 +1262,220 @@ func (p *Parser) parseFunction() (ast.Node, error) {
 	return fn, err
 }
 
+func (p *Parser) parseNew() (ast.Node, error) {
+	node := ast.NewNode{
+		Args:     ast.SeqNode{},
+		Position: p.curr.Position,
+	}
+	p.next()
+	fn, ok := p.prefix[p.curr.Type]
+	if !ok {
+		return nil, p.unexpected()
+	}
+	ident, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	for p.is(token.Dot) || p.is(token.Lsquare) {
+		if p.is(token.Dot) {
+			ident, err = p.parseMember(ident)
+		} else {
+			ident, err = p.parseIndex(ident)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	node.Ident = ident
+	if p.is(token.Lparen) {
+		node.Args, err = p.parseGroup()
+	}
+	return node, err
+}
+
+func (p *Parser) parseThis() (ast.Node, error) {
+	defer p.next()
+	return ast.ThisNode{}, nil
+}
+
+func (p *Parser) parseSuper() (ast.Node, error) {
+	defer p.next()
+	if p.peek.Type != token.Lparen && p.peek.Type != token.Dot {
+		return nil, p.unexpected()
+	}
+	return ast.SuperNode{}, nil
+}
+
+func (p *Parser) parseClass() (ast.Node, error) {
+	p.next()
+	var (
+		node ast.ClassNode
+		err  error
+	)
+	if p.is(token.Ident) {
+		node.Ident = p.curr.Literal
+		p.next()
+	}
+	if p.is(token.Keyword) && p.curr.Literal == "extends" {
+		p.next()
+		if node.Parent, err = p.parseNode(powObject - 1); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect(token.Lbrace); err != nil {
+		return nil, err
+	}
+	p.skip(token.EOL)
+	for !p.done() && !p.is(token.Rbrace) {
+		member, err := p.parseClassMember()
+		if err != nil {
+			return nil, err
+		}
+		if m, ok := member.(ast.MethodNode); ok && !m.Static && m.Ident == "constructor" {
+			if node.Ctor != nil {
+				return nil, fmt.Errorf("class: constructor already defined")
+			}
+			node.Ctor = m.Func
+		} else {
+			node.Members = append(node.Members, member)
+		}
+		p.skip(token.EOL)
+	}
+	return node, p.expect(token.Rbrace)
+}
+
+func (p *Parser) parseClassMember() (ast.Node, error) {
+	var static bool
+	if p.is(token.Ident) && p.curr.Literal == "static" && p.peek.Type != token.Lparen && p.peek.Type != token.Assign {
+		static = true
+		p.next()
+	}
+	var async bool
+	if p.is(token.Keyword) && p.curr.Literal == "async" && p.peek.Type != token.Lparen && p.peek.Type != token.Assign {
+		async = true
+		p.next()
+	}
+	var generator bool
+	if p.is(token.Mul) {
+		generator = true
+		p.next()
+	}
+	if !generator && p.isAccessor() {
+		kind := p.curr.Literal
+		p.next()
+		node := ast.AccessorNode{
+			Static: static,
+		}
+		if err := p.parseAccessorKey(&node); err != nil {
+			return nil, err
+		}
+		return node, p.parseAccessor(kind, &node)
+	}
+	var (
+		ident string
+		key   ast.Node
+		err   error
+	)
+	switch {
+	case p.is(token.Lsquare):
+		if key, err = p.parseComputedKey(); err != nil {
+			return nil, err
+		}
+		if !p.is(token.Lparen) {
+			return nil, p.unexpected()
+		}
+	case p.is(token.Ident), p.is(token.Keyword), p.is(token.String):
+		ident = p.curr.Literal
+		p.next()
+	default:
+		return nil, p.unexpected()
+	}
+	if p.is(token.Lparen) {
+		fn := ast.FuncNode{
+			Ident:     ident,
+			Generator: generator,
+			Async:     async,
+		}
+		if fn.Args, err = p.parseArgs(); err != nil {
+			return nil, err
+		}
+		if fn.Body, err = p.parseBody(); err != nil {
+			return nil, err
+		}
+		node := ast.MethodNode{
+			Ident:  ident,
+			Key:    key,
+			Static: static,
+			Func:   fn,
+		}
+		return node, nil
+	}
+	node := ast.FieldNode{
+		Ident:  ident,
+		Static: static,
+	}
+	if p.is(token.Assign) {
+		p.next()
+		expr, err := p.parseNode(powComma)
+		if err != nil {
+			return nil, err
+		}
+		node.Expr = expr
+	}
+	return node, nil
+}
+
+func (p *Parser) parseAccessor(kind string, node *ast.AccessorNode) error {
+	var (
+		fn = ast.FuncNode{
+			Ident: node.Ident,
+		}
+		err error
+	)
+	if fn.Args, err = p.parseArgs(); err != nil {
+		return err
+	}
+	seq, _ := fn.Args.(ast.SeqNode)
+	switch {
+	case kind == "get" && len(seq.Nodes) != 0:
+		return fmt.Errorf("getter %s should not have parameters", node.Ident)
+	case kind == "set" && len(seq.Nodes) != 1:
+		return fmt.Errorf("setter %s should have exactly one parameter", node.Ident)
+	}
+	if fn.Body, err = p.parseBody(); err != nil {
+		return err
+	}
+	if kind == "get" {
+		node.Get = fn
+	} else {
+		node.Set = fn
+	}
+	return nil
+}
+
+func (p *Parser) parseAccessorKey(node *ast.AccessorNode) error {
+	if !p.is(token.Lsquare) {
+		node.Ident = p.curr.Literal
+		p.next()
+		return nil
+	}
+	key, err := p.parseComputedKey()
+	node.Key = key
+	return err
+}
+
+func (p *Parser) isAccessor() bool {
+	if !p.is(token.Ident) || (p.curr.Literal != "get" && p.curr.Literal != "set") {
+		return false
+	}
+	switch p.peek.Type {
+	case token.Ident, token.Keyword, token.String, token.Number, token.Lsquare:
+		return true
+	default:
+		return false
+	}
+}
+
 func (p *Parser) parseArgs() (ast.Node, error) {
 	p.enableDestructuring()
 	defer p.disableDestructuring()
@@ -1074,6 +1518,53 @@ func (p *Parser) parseReturn() (ast.Node, error) {
 	return ret, err
 }
 
+func (p *Parser) parseYield() (ast.Node, error) {
+	p.next()
+	var (
+		node ast.YieldNode
+		err  error
+	)
+	if p.is(token.Mul) {
+		node.Delegate = true
+		p.next()
+	}
+	switch {
+	case p.done() || p.eol():
+	case p.is(token.Rparen) || p.is(token.Rsquare) || p.is(token.Rbrace) || p.is(token.Comma):
+	default:
+		node.Node, err = p.parseNode(powComma)
+	}
+	return node, err
+}
+
+func (p *Parser) parseAsync() (ast.Node, error) {
+	p.next()
+	node, err := p.parseNode(powComma)
+	if err != nil {
+		return nil, err
+	}
+	switch n := node.(type) {
+	case ast.FuncNode:
+		n.Async = true
+		return n, nil
+	case ast.ArrowNode:
+		n.Async = true
+		return n, nil
+	default:
+		return nil, fmt.Errorf("async: function expected")
+	}
+}
+
+func (p *Parser) parseAwait() (ast.Node, error) {
+	p.next()
+	var (
+		node ast.AwaitNode
+		err  error
+	)
+	node.Node, err = p.parseNode(powUnary)
+	return node, err
+}
+
 func (p *Parser) parseBinary(left ast.Node) (ast.Node, error) {
 	bin := ast.BinaryNode{
 		Op:   p.curr.Type,
@@ -1092,7 +1583,17 @@ func (p *Parser) parseAssign(left ast.Node) (ast.Node, error) {
 	var (
 		node = makeAssignNode(left)
 		op   = p.curr.Type
+		err  error
 	)
+	switch left.(type) {
+	case ast.ArrayNode, ast.ObjectNode:
+		if op != token.Assign {
+			return nil, p.unexpected()
+		}
+		if node.Ident, err = p.parseAssignPattern(left); err != nil {
+			return nil, err
+		}
+	}
 	p.next()
 
 	if p.isDestructuringAllowed() {
@@ -1104,17 +1605,10 @@ func (p *Parser) parseAssign(left ast.Node) (ast.Node, error) {
 	if err != nil {
 		return nil, err
 	}
-	op, err = token.ConvertAssignToken(op)
+	node.Op, err = token.ConvertAssignToken(op)
 	if err != nil {
 		return nil, err
 	}
-	if op != token.Assign {
-		expr = ast.BinaryNode{
-			Op:    op,
-			Left:  left,
-			Right: expr,
-		}
-	}
 	node.Expr = expr
 	return node, nil
 }
@@ -1132,7 +1626,7 @@ func (p *Parser) parseTernary(left ast.Node) (ast.Node, error) {
 	if err = p.expect(token.Colon); err != nil {
 		return nil, err
 	}
-	node.Alt, err = p.parseNode(powLowest)
+	node.Alt, err = p.parseNode(powComma)
 	if err != nil {
 		return nil, err
 	}
@@ -1156,7 +1650,7 @@ func (p *Parser) parseIndex(left ast.Node) (ast.Node, error) {
 }
 
 func (p *Parser) parseCall(left ast.Node) (ast.Node, error) {
-	call := makeCall(left)
+	call := makeCall(left, p.curr.Position)
 	args, err := p.parseGroup()
 	if err != nil {
 		return nil, err
@@ -1170,7 +1664,9 @@ func (p *Parser) parseArrow(left ast.Node) (ast.Node, error) {
 		fn  ast.ArrowNode
 		err error
 	)
-	fn.Args = left
+	if fn.Args, err = p.parseParams(left); err != nil {
+		return nil, err
+	}
 	p.next()
 	switch {
 	case p.is(token.Lparen):
@@ -1182,16 +1678,129 @@ func (p *Parser) parseArrow(left ast.Node) (ast.Node, error) {
 	case p.is(token.Lbrace):
 		fn.Body, err = p.parseBody()
 	default:
-		fn.Body, err = p.parseNode(powLowest)
+		fn.Body, err = p.parseNode(powComma)
 	}
 	return fn, err
 }
 
+func (p *Parser) parseOptional(left ast.Node) (ast.Node, error) {
+	p.next()
+	var (
+		node ast.Node
+		err  error
+	)
+	switch {
+	case p.is(token.Lsquare):
+		node, err = p.parseIndex(left)
+	case p.is(token.Lparen):
+		node, err = p.parseCall(left)
+	default:
+		node, err = p.parseProperty(left)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return makeOptional(node), nil
+}
+
+func (p *Parser) parseParams(left ast.Node) (ast.Node, error) {
+	seq, ok := left.(ast.SeqNode)
+	if !ok {
+		return p.parsePattern(left)
+	}
+	for i := range seq.Nodes {
+		n, err := p.parsePattern(seq.Nodes[i])
+		if err != nil {
+			return nil, err
+		}
+		seq.Nodes[i] = n
+	}
+	return seq, nil
+}
+
+func (p *Parser) parsePattern(n ast.Node) (ast.Node, error) {
+	return p.toPattern(n, false)
+}
+
+func (p *Parser) parseAssignPattern(n ast.Node) (ast.Node, error) {
+	return p.toPattern(n, true)
+}
+
+func (p *Parser) toPattern(n ast.Node, assign bool) (ast.Node, error) {
+	switch n := n.(type) {
+	case ast.VarNode, ast.DiscardNode, ast.BindingArrayNode, ast.BindingObjectNode:
+		return n, nil
+	case ast.MemberNode, ast.IndexNode:
+		if !assign {
+			return nil, p.unexpected()
+		}
+		return n, nil
+	case ast.AssignNode:
+		if n.Op != 0 && n.Op != token.Assign {
+			return nil, p.unexpected()
+		}
+		id, err := p.toPattern(n.Ident, assign)
+		if err != nil {
+			return nil, err
+		}
+		return ast.AssignNode{
+			Ident: id,
+			Expr:  n.Expr,
+		}, nil
+	case ast.SpreadNode:
+		id, err := p.toPattern(n.Node, assign)
+		return makeSpreadFrom(id), err
+	case ast.ArrayNode:
+		var list []ast.Node
+		for _, n := range n.List {
+			b, err := p.toPattern(n, assign)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, b)
+		}
+		return ast.BindArray(list), nil
+	case ast.ObjectNode:
+		var list []ast.Node
+		for _, n := range n.List {
+			switch n := n.(type) {
+			case ast.PropNode:
+				b, err := p.toPattern(n.Value, assign)
+				if err != nil {
+					return nil, err
+				}
+				n.Value = b
+				list = append(list, n)
+			case ast.SpreadNode:
+				b, err := p.toPattern(n, assign)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, b)
+			default:
+				return nil, p.unexpected()
+			}
+		}
+		return ast.BindObject(list), nil
+	default:
+		return nil, p.unexpected()
+	}
+}
+
 func (p *Parser) parseMember(left ast.Node) (ast.Node, error) {
 	p.next()
+	return p.parseProperty(left)
+}
+
+func (p *Parser) parseProperty(left ast.Node) (ast.Node, error) {
 	node := ast.MemberNode{
 		Curr: left,
 	}
+	if p.is(token.Keyword) {
+		node.Next = ast.CreateVar(p.curr.Literal)
+		p.next()
+		return node, nil
+	}
 	next, err := p.parseNode(powObject)
 	if err != nil {
 		return nil, err
@@ -1213,8 +1822,24 @@ func (p *Parser) parseUnary() (ast.Node, error) {
 	return node, err
 }
 
+func (p *Parser) parsePostfix(left ast.Node) (ast.Node, error) {
+	defer p.next()
+	node := ast.PostfixNode{
+		Op:   p.curr.Type,
+		Expr: left,
+	}
+	return node, nil
+}
+
 func (p *Parser) parseNumber() (ast.Node, error) {
 	defer p.next()
+	if lit := p.curr.Literal; len(lit) > 1 && lit[0] == '0' && strings.ContainsRune("box", rune(lit[1])) {
+		n, err := strconv.ParseInt(lit, 0, 64)
+		if err != nil {
+			return nil, err
+		}
+		return ast.CreateValue(float64(n)), nil
+	}
 	n, err := strconv.ParseFloat(p.curr.Literal, 64)
 	if err != nil {
 		return nil, err
@@ -1227,6 +1852,23 @@ func (p *Parser) parseString() (ast.Node, error) {
 	return ast.CreateValue(p.curr.Literal), nil
 }
 
+func (p *Parser) parseRegex() (ast.Node, error) {
+	p.curr = p.scan.ScanRegex()
+	p.peek = p.scan.Scan()
+	if !p.is(token.Regex) {
+		return nil, p.unexpected()
+	}
+	defer p.next()
+	var (
+		ix   = strings.LastIndex(p.curr.Literal, "/")
+		node = ast.RegexNode{
+			Pattern: p.curr.Literal[:ix],
+			Flags:   p.curr.Literal[ix+1:],
+		}
+	)
+	return node, nil
+}
+
 func (p *Parser) parseBool() (ast.Node, error) {
 	defer p.next()
 	n, err := strconv.ParseBool(p.curr.Literal)
@@ -1237,13 +1879,8 @@ func (p *Parser) parseBool() (ast.Node, error) {
 }
 
 func (p *Parser) parseIdentifier() (ast.Node, error) {
-	node := ast.CreateVar(p.curr.Literal)
-	p.next()
-	if p.is(token.Colon) {
-		p.next()
-		return ast.Label(node.Ident), nil
-	}
-	return node, nil
+	defer p.next()
+	return ast.CreateVar(p.curr.Literal), nil
 }
 
 func (p *Parser) parseKeyword() (ast.Node, error) {
@@ -1294,7 +1931,7 @@ func (p *Parser) parseBody() (ast.Node, error) {
 	p.skip(token.EOL)
 	var b ast.BlockNode
 	for !p.done() && !p.is(token.Rbrace) {
-		n, err := p.parseNode(powLowest)
+		n, err := p.parseStatement()
 		if err != nil {
 			return nil, err
 		}
@@ -1327,6 +1964,7 @@ func (p *Parser) reset() {
 	}
 	p.scan.Reset()
 	p.resetDestructuring()
+	p.noIn = false
 }
 
 func (p *Parser) isDestructuringAllowed() bool {
@@ -1364,6 +2002,17 @@ func (p *Parser) skip(kind rune) {
 }
 
 func (p *Parser) power() int {
+	if p.is(token.Keyword) {
+		switch p.curr.Literal {
+		case "instanceof":
+			return powCompare
+		case "in":
+			if !p.noIn {
+				return powCompare
+			}
+		}
+		return powLowest
+	}
 	return powers.Get(p.curr.Type)
 }
 
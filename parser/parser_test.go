@@ -11,6 +11,17 @@ func TestParser(t *testing.T) {
 		"testdata/control.js",
 		"testdata/func.js",
 		"testdata/export.js",
+		"testdata/class.js",
+		"testdata/generator.js",
+		"testdata/async.js",
+		"testdata/regex.js",
+		"testdata/optional.js",
+		"testdata/assign.js",
+		"testdata/hoist.js",
+		"testdata/params.js",
+		"testdata/object.js",
+		"testdata/destructuring.js",
+		"testdata/symbol.js",
 	}
 	for _, f := range files {
 		parseFile(t, f)
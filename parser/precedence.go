@@ -46,6 +46,7 @@ var powers = powerSet{
 	token.OrAssign:      powAssign,
 	token.LshiftAssign:  powAssign,
 	token.RshiftAssign:  powAssign,
+	token.UrshiftAssign: powAssign,
 	token.BandAssign:    powAssign,
 	token.BorAssign:     powAssign,
 	token.BxorAssign:    powAssign,
@@ -65,12 +66,15 @@ var powers = powerSet{
 	token.Ge:            powCompare,
 	token.Lshift:        powShift,
 	token.Rshift:        powShift,
+	token.Urshift:       powShift,
 	token.Add:           powAdd,
 	token.Sub:           powAdd,
 	token.Mul:           powMul,
 	token.Div:           powMul,
 	token.Mod:           powMul,
 	token.Pow:           powPow,
+	token.Increment:     powPostfix,
+	token.Decrement:     powPostfix,
 	token.Dot:           powObject,
 	token.Optional:      powObject,
 	token.Lparen:        powObject,
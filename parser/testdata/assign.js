@@ -0,0 +1,9 @@
+counter.hits++
+--stack[top]
+matrix[row][col] += delta
+total **= 2
+flags >>>= 1
+mask <<= shift
+bits ^= 0xff
+flags = ~flags & 0b1010 | 0o17
+options.level ??= 1
@@ -0,0 +1,19 @@
+async function fetchAll(urls) {
+  const results = []
+  for (const url of urls) {
+    results.push(await fetch(url))
+  }
+  return results
+}
+
+const double = async (x) => x * 2
+const single = async x => await double(x)
+
+class Client {
+  async get(path) {
+    return await this.request(path)
+  }
+  static async create() {
+    return new Client()
+  }
+}
@@ -0,0 +1,45 @@
+class Animal {
+  legs = 4
+  static count = 0
+  constructor(name) {
+    this.name = name
+  }
+  speak() {
+    return this.name
+  }
+  static create(name) {
+    return new Animal(name)
+  }
+}
+
+class Dog extends Animal {
+  constructor(name) {
+    super(name)
+  }
+  speak() {
+    return super.speak()
+  }
+}
+
+const Anon = class {}
+const dog = new Dog("rex")
+const other = new Animal
+
+class Temperature {
+  get celsius() {
+    return this.value
+  }
+  set celsius(v) {
+    this.value = v
+  }
+  static get unit() {
+    return "C"
+  }
+}
+
+const point = {
+  x: 1,
+  get double() {
+    return this.x * 2
+  },
+}
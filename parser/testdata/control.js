@@ -84,3 +84,25 @@ case 2:
 default:
   console.log("default")
 }
+
+outer:
+for (const row of rows) {
+  for (const cell of row) {
+    if (!cell) {
+      continue outer
+    }
+    break outer
+  }
+}
+
+try {
+  throw { message: "boom" }
+} catch ({ message }) {
+  console.log(message)
+}
+
+try {
+  risky()
+} catch {
+  console.log("ignored")
+}
@@ -0,0 +1,19 @@
+const { id, name: label = "none", tags: [primary, ...secondary] = [], ...rest } = record
+const [head, , [nested = 1], { deep }, ...tail] = list
+const { [key]: computed, "quoted": q } = record
+
+let first = 1
+let second = 2;
+[first, second] = [second, first];
+({ first, second = 3 } = pair);
+[target.a, target["b"]] = values
+
+for ([first, second] of entries) {
+  first
+}
+
+try {
+  run()
+} catch ({ message, cause: { code } = {} }) {
+  message
+}
@@ -0,0 +1,25 @@
+function* range(start, end) {
+  for (let i = start; i < end; i++) {
+    const skip = yield i
+    if (skip) {
+      i += skip
+    }
+  }
+}
+
+function* flatten(list) {
+  for (const item of list) {
+    yield* item
+  }
+  yield
+}
+
+const gen = function* () {
+  yield 1
+}
+
+class Tree {
+  *walk() {
+    yield* this.nodes
+  }
+}
@@ -0,0 +1,19 @@
+var count = 0
+var [first, second] = pair
+var empty
+
+for (var i = 0; i < 10; i++) {
+  count += i
+}
+
+for (var key in table) {
+  var last = key
+}
+
+for (var item of list) {
+  total = sum(total, item)
+}
+
+function sum(a, b) {
+  return a + b
+}
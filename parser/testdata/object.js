@@ -0,0 +1,28 @@
+const key = "name"
+const defaults = { color: "red", size: 1 }
+
+const record = {
+  id: 1,
+  [key]: "enjoy",
+  [`${key}Upper`]: "ENJOY",
+  "quoted": true,
+  42: "answer",
+  defaults,
+  ...defaults,
+  size: 2,
+  describe() {
+    return this.name
+  },
+  async load(url) {
+    return url
+  },
+  *entries() {
+    yield this.id
+  },
+  get label() {
+    return this.name
+  },
+  set label(value) {
+    this.name = value
+  },
+}
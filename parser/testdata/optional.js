@@ -0,0 +1,6 @@
+const street = user?.address?.street
+const first = list?.[0]?.name
+const result = callback?.(1, 2)
+const count = obj.items?.length ?? 0
+
+config?.plugins.forEach(p => p?.init?.())
@@ -0,0 +1,7 @@
+function sum(first, ...rest) {
+  return rest.reduce((acc, n) => acc + n, first)
+}
+
+const pick = ({ name, age: years = 0 }, [head, ...tail], ...others) => name
+const scale = (factor = 2) => factor * arguments.length
+const call = () => sum(...values, 1, ...more)
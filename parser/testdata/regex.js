@@ -0,0 +1,10 @@
+const date = /(?<year>\d{4})-(?<month>\d{2})/g
+const slash = /a[/]b\/c/i
+const ratio = total / count / 2
+
+if (/^\s*$/.test(line)) {
+  line = line.replace(/=+/g, "=")
+}
+
+const parts = text.split(/[,;]\s*/)
+const path = `${dir}/${file}`
@@ -0,0 +1,32 @@
+const id = Symbol("id")
+const shared = Symbol.for("shared")
+
+const record = {
+  [id]: 1,
+  get [Symbol.toStringTag]() {
+    return "Record"
+  },
+  [Symbol.toPrimitive](hint) {
+    return hint
+  },
+}
+
+class Collection {
+  constructor(items) {
+    this.items = items
+  }
+
+  *[Symbol.iterator]() {
+    yield this.items
+  }
+
+  get [Symbol.toStringTag]() {
+    return "Collection"
+  }
+
+  static [shared](items) {
+    return new Collection(items)
+  }
+}
+
+record[shared] = Symbol.keyFor(shared)
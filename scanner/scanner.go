@@ -12,10 +12,12 @@ import (
 type Scanner struct {
 	input []byte
 	cursor
-	old cursor
+	old   cursor
+	marks [2]mark
 
 	str        bytes.Buffer
 	mode       scanMode
+	depth      int
 	keepAllEol bool
 }
 
@@ -34,6 +36,7 @@ func Scan(r io.Reader) *Scanner {
 
 func (s *Scanner) Reset() {
 	s.mode = modeDefault
+	s.depth = 0
 	s.keepAllEol = false
 }
 
@@ -44,6 +47,7 @@ func (s *Scanner) ToggleKeepEOL() {
 func (s *Scanner) Scan() token.Token {
 	defer s.reset()
 
+	s.marks[0], s.marks[1] = s.marks[1], s.mark()
 	switch s.mode {
 	case modeTpl:
 		return s.scanTemplate()
@@ -54,6 +58,28 @@ func (s *Scanner) Scan() token.Token {
 	}
 }
 
+func (s *Scanner) ScanRegex() token.Token {
+	defer s.reset()
+
+	s.cursor, s.mode, s.depth = s.marks[0].cursor, s.marks[0].mode, s.marks[0].depth
+	s.marks[1] = s.marks[0]
+
+	s.skip(isBlank)
+	tok := s.prepare()
+	s.scanRegex(&tok)
+	s.skip(isBlank)
+	s.discardNL()
+	return tok
+}
+
+func (s *Scanner) mark() mark {
+	return mark{
+		cursor: s.cursor,
+		mode:   s.mode,
+		depth:  s.depth,
+	}
+}
+
 func (s *Scanner) prepare() token.Token {
 	var tok token.Token
 	tok.Offset = s.curr
@@ -80,10 +106,17 @@ func (s *Scanner) scanTemplate() token.Token {
 }
 
 func (s *Scanner) scanSubstitution() token.Token {
-	if s.char == rbrace {
+	if s.char == rbrace && s.depth == 0 {
 		return s.toggleSubstitution()
 	}
-	return s.scanDefault()
+	tok := s.scanDefault()
+	switch tok.Type {
+	case token.Lbrace:
+		s.depth++
+	case token.Rbrace:
+		s.depth--
+	}
+	return tok
 }
 
 func (s *Scanner) scanDefault() token.Token {
@@ -182,6 +215,39 @@ func (s *Scanner) scanComment(tok *token.Token) {
 	tok.Literal = s.literal()
 }
 
+func (s *Scanner) scanRegex(tok *token.Token) {
+	tok.Type = token.Invalid
+	if s.char != slash {
+		return
+	}
+	s.read()
+	var class bool
+	for !s.done() && !isNL(s.char) && (class || s.char != slash) {
+		switch s.char {
+		case backslash:
+			s.write()
+			s.read()
+		case lsquare:
+			class = true
+		case rsquare:
+			class = false
+		}
+		s.write()
+		s.read()
+	}
+	if s.char != slash {
+		return
+	}
+	s.read()
+	s.writeRune(slash)
+	for isLetter(s.char) {
+		s.write()
+		s.read()
+	}
+	tok.Type = token.Regex
+	tok.Literal = s.literal()
+}
+
 func (s *Scanner) scanString(tok *token.Token) {
 	quote := s.char
 	s.read()
@@ -192,10 +258,7 @@ func (s *Scanner) scanString(tok *token.Token) {
 			s.read()
 			char, ok := escapes[s.char]
 			if !ok {
-				tok.Type = token.Invalid
-				s.writeRune(backslash)
-				s.writeRune(char)
-				continue
+				char = s.char
 			}
 			if s.char == 'x' {
 				s.read()
@@ -203,6 +266,8 @@ func (s *Scanner) scanString(tok *token.Token) {
 			} else if s.char == 'u' {
 				s.read()
 				char = s.runeFromRunes(4)
+			} else {
+				s.read()
 			}
 			s.writeRune(char)
 			if char == utf8.RuneError {
@@ -242,7 +307,7 @@ func (s *Scanner) scanDigits(tok *token.Token, accept func(rune) bool) {
 
 func (s *Scanner) scanNumber(tok *token.Token) {
 	tok.Type = token.Number
-	if k := s.peek(); s.char == '0' && k == 'b' || k == 'x' || k == 'o' {
+	if k := s.peek(); s.char == '0' && (k == 'b' || k == 'x' || k == 'o') {
 		s.write()
 		s.read()
 		switch s.char {
@@ -466,6 +531,13 @@ func (s *Scanner) scanPunct(tok *token.Token) {
 			if s.peek() == equal {
 				s.read()
 				tok.Type = token.RshiftAssign
+			} else if s.peek() == rangle {
+				s.read()
+				tok.Type = token.Urshift
+				if s.peek() == equal {
+					s.read()
+					tok.Type = token.UrshiftAssign
+				}
 			}
 		}
 	case question:
@@ -575,6 +647,12 @@ const (
 	modeSub
 )
 
+type mark struct {
+	cursor
+	mode  scanMode
+	depth int
+}
+
 type cursor struct {
 	char rune
 	curr int
@@ -664,7 +742,7 @@ func isOctal(r rune) bool {
 }
 
 func isHex(r rune) bool {
-	return isDigit(r) || (r >= 'a' && r <= 'f') && (r >= 'A' && r <= 'F')
+	return isDigit(r) || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
 }
 
 func isAlpha(r rune) bool {
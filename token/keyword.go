@@ -7,6 +7,7 @@ import (
 var keywords = []string{
 	"as",
 	"let",
+	"var",
 	"const",
 	"for",
 	"in",
@@ -24,6 +25,7 @@ var keywords = []string{
 	"break",
 	"continue",
 	"try",
+	"throw",
 	"catch",
 	"finally",
 	"while",
@@ -36,6 +38,13 @@ var keywords = []string{
 	"typeof",
 	"instanceof",
 	"new",
+	"this",
+	"class",
+	"extends",
+	"super",
+	"yield",
+	"async",
+	"await",
 }
 
 func IsKeyword(str string) bool {
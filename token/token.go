@@ -139,6 +139,8 @@ func (t Token) String() string {
 		prefix = "number"
 	case Boolean:
 		prefix = "boolean"
+	case Regex:
+		prefix = "regex"
 	case Comment:
 		prefix = "comment"
 	case Ident:
@@ -13,6 +13,7 @@ const (
 	String
 	Number
 	Boolean
+	Regex
 	Dot
 	Template
 	BegSub
@@ -55,6 +56,8 @@ const (
 	LshiftAssign
 	Rshift
 	RshiftAssign
+	Urshift
+	UrshiftAssign
 	Band
 	BandAssign
 	Bor
@@ -101,6 +104,8 @@ func ConvertAssignToken(op rune) (rune, error) {
 		op = Lshift
 	case RshiftAssign:
 		op = Rshift
+	case UrshiftAssign:
+		op = Urshift
 	case BandAssign:
 		op = Band
 	case BorAssign:
@@ -4,13 +4,13 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"strconv"
 	"strings"
-
-	"github.com/midbel/enjoy/env"
 )
 
 type Array struct {
 	values []Value
+	props  *Object
 }
 
 func CreateArray(vs []Value) Value {
@@ -23,8 +23,18 @@ func (a *Array) True() bool {
 	return a.Len() > 0
 }
 
-func (a *Array) Spread() []Value {
-	return slices.Clone(a.values)
+func (a *Array) Spread() ([]Value, error) {
+	return slices.Clone(a.values), nil
+}
+
+func (a *Array) Iterate() Iterator {
+	return &arrayIterator{
+		arr: a,
+	}
+}
+
+func (a *Array) Enumerate() []Value {
+	return enumerateIndex(a.Len())
 }
 
 func (a *Array) Len() int {
@@ -43,11 +53,27 @@ func (a *Array) At(ix Value) (Value, error) {
 	return a.values[i], nil
 }
 
+func (a *Array) SetAt(ix Value, val Value) error {
+	x, ok := ix.(Float)
+	if !ok || x.value < 0 {
+		return ErrOperation
+	}
+	i := int(x.value)
+	for len(a.values) <= i {
+		a.values = append(a.values, Undefined())
+	}
+	a.values[i] = val
+	return nil
+}
+
 func (a *Array) Get(prop string) (Value, error) {
-	if prop != "length" {
+	if prop == "length" {
+		return CreateFloat(float64(len(a.values))), nil
+	}
+	if a.props == nil {
 		return Undefined(), nil
 	}
-	return CreateFloat(float64(len(a.values))), nil
+	return a.props.Get(prop)
 }
 
 func (a *Array) Call(fn string, args []Value) (Value, error) {
@@ -93,23 +119,23 @@ var arrayPrototype = map[string]ValueFunc[*Array]{
 	"includes":      CheckArity(1, arrayIncludes),
 	"indexOf":       CheckArity(1, arrayIndexOf),
 	"join":          CheckArity(0, arrayJoin),
-	// "keys":          arrayKeys,
-	"lastIndexOf": CheckArity(1, arrayLastIndexOf),
-	"map":         CheckArity(1, arrayMap),
-	"pop":         CheckArity(0, arrayPop),
-	"push":        CheckArity(1, arrayPush),
-	"reduce":      CheckArity(1, arrayReduce),
-	"reduceRight": CheckArity(1, arrayReduceRight),
-	"reverse":     CheckArity(0, arrayReverse),
-	"shift":       CheckArity(0, arrayShift),
-	"slice":       CheckArity(0, arraySlice),
-	"some":        CheckArity(1, arraySome),
-	"sort":        CheckArity(0, arraySort),
-	"splice":      CheckArity(1, arraySplice),
-	"toString":    CheckArity(0, arrayToString),
-	"unshift":     CheckArity(0, arrayUnshift),
-	// "values":        arrayValues,
-	"with": CheckArity(2, arrayWith),
+	"keys":          CheckArity(0, arrayKeys),
+	"lastIndexOf":   CheckArity(1, arrayLastIndexOf),
+	"map":           CheckArity(1, arrayMap),
+	"pop":           CheckArity(0, arrayPop),
+	"push":          CheckArity(1, arrayPush),
+	"reduce":        CheckArity(1, arrayReduce),
+	"reduceRight":   CheckArity(1, arrayReduceRight),
+	"reverse":       CheckArity(0, arrayReverse),
+	"shift":         CheckArity(0, arrayShift),
+	"slice":         CheckArity(0, arraySlice),
+	"some":          CheckArity(1, arraySome),
+	"sort":          CheckArity(0, arraySort),
+	"splice":        CheckArity(1, arraySplice),
+	"toString":      CheckArity(0, arrayToString),
+	"unshift":       CheckArity(0, arrayUnshift),
+	"values":        CheckArity(0, arrayValues),
+	"with":          CheckArity(2, arrayWith),
 }
 
 func arrayAt(a *Array, args []Value) (Value, error) {
@@ -129,7 +155,13 @@ func arrayConcat(a *Array, args []Value) (Value, error) {
 }
 
 func arrayEntries(a *Array, args []Value) (Value, error) {
-	return nil, ErrImplemented
+	it := arrayIterator{
+		arr: a,
+		read: func(i int, v Value) Value {
+			return CreateArray([]Value{CreateFloat(float64(i)), v})
+		},
+	}
+	return CreateIterator("Array Iterator", &it), nil
 }
 
 func arrayEvery(a *Array, args []Value) (Value, error) {
@@ -291,55 +323,18 @@ func arrayFlat(a *Array, args []Value) (Value, error) {
 }
 
 func arrayFlatMap(a *Array, args []Value) (Value, error) {
-	fn, ok := args[0].(Func)
-	if !ok {
-		return nil, ErrOperation
-	}
-	var (
-		ident   string
-		index   string
-		array   string
-		err     error
-		flatten func(Value, Value, int) ([]Value, error)
-	)
-
-	if len(fn.Params) >= 1 {
-		ident = fn.Params[0].Name
-	}
-	if len(fn.Params) >= 2 {
-		index = fn.Params[1].Name
-	}
-	if len(fn.Params) >= 3 {
-		array = fn.Params[2].Name
-	}
-
-	flatten = func(v, arr Value, i int) ([]Value, error) {
-		a, ok := v.(*Array)
-		if !ok {
-			tmp := env.EnclosedEnv[Value](fn.Env)
-			if ident != "" {
-				tmp.Define(ident, v, false)
-			}
-			if index != "" {
-				tmp.Define(index, CreateFloat(float64(i)), false)
-			}
-			if array != "" {
-				tmp.Define(array, arr, false)
-			}
-			v, err := fn.Body.Eval(tmp)
-			return []Value{v}, err
+	var list []Value
+	err := arrayApplyFunc(a, args, func(v Value, _ int, err error) error {
+		if err != nil {
+			return err
 		}
-		var list []Value
-		for i := range a.values {
-			xs, err := flatten(a.values[i], a, i)
-			if err != nil {
-				return nil, err
-			}
-			list = append(list, xs...)
+		if arr, ok := v.(*Array); ok {
+			list = append(list, arr.values...)
+		} else {
+			list = append(list, v)
 		}
-		return list, nil
-	}
-	list, err := flatten(a, a, 0)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -420,7 +415,13 @@ func arrayJoin(a *Array, args []Value) (Value, error) {
 }
 
 func arrayKeys(a *Array, args []Value) (Value, error) {
-	return nil, ErrImplemented
+	it := arrayIterator{
+		arr: a,
+		read: func(i int, _ Value) Value {
+			return CreateFloat(float64(i))
+		},
+	}
+	return CreateIterator("Array Iterator", &it), nil
 }
 
 func arrayLastIndexOf(a *Array, args []Value) (Value, error) {
@@ -605,7 +606,7 @@ func arrayUnshift(a *Array, args []Value) (Value, error) {
 }
 
 func arrayValues(a *Array, args []Value) (Value, error) {
-	return nil, ErrImplemented
+	return CreateIterator("Array Iterator", a.Iterate()), nil
 }
 
 func arrayWith(a *Array, args []Value) (Value, error) {
@@ -624,86 +625,43 @@ func arrayWith(a *Array, args []Value) (Value, error) {
 }
 
 func arrayApplyReduce(arr *Array, args []Value) (Value, error) {
-	fn, ok := args[0].(Func)
-	if !ok {
-		return nil, ErrOperation
-	}
 	var (
-		accum   string
-		current string
-		index   string
-		array   string
-		res     = Undefined()
-		err     error
+		fn     = args[0]
+		values = arr.values
+		res    Value
 	)
-	if len(fn.Params) >= 1 {
-		accum = fn.Params[0].Name
-	}
-	if len(fn.Params) >= 2 {
-		current = fn.Params[1].Name
-	}
-	if len(fn.Params) >= 3 {
-		index = fn.Params[2].Name
-	}
-	if len(fn.Params) >= 4 {
-		index = fn.Params[3].Name
+	if !isFunction(fn) {
+		return nil, fmt.Errorf("%s is not a function: %w", fn, ErrOperation)
 	}
 	if len(args) >= 2 {
 		res = args[1]
+	} else if len(values) > 0 {
+		res, values = values[0], values[1:]
+	} else {
+		return nil, fmt.Errorf("reduce of empty array with no initial value: %w", ErrOperation)
 	}
-	for i := range arr.values {
-		tmp := env.EnclosedEnv[Value](fn.Env)
-		if accum != "" {
-			tmp.Define(accum, res, false)
-		}
-		if current != "" {
-			tmp.Define(current, arr.values[i], false)
-		}
-		if index != "" {
-			tmp.Define(index, CreateFloat(float64(i)), false)
-		}
-		if array != "" {
-			tmp.Define(array, arr, false)
-		}
-		res, err = fn.Body.Eval(tmp)
+	offset := len(arr.values) - len(values)
+	for i, v := range values {
+		var err error
+		res, err = Invoke(fn, Undefined(), []Value{res, v, CreateFloat(float64(i + offset)), arr})
 		if err != nil {
-			break
+			return nil, err
 		}
 	}
-	return res, err
+	return res, nil
 }
 
 func arrayApplyFunc(a *Array, args []Value, apply func(v Value, i int, err error) error) error {
-	fn, ok := args[0].(Func)
-	if !ok {
-		return ErrOperation
+	fn := args[0]
+	if !isFunction(fn) {
+		return fmt.Errorf("%s is not a function: %w", fn, ErrOperation)
 	}
-	var (
-		ident string
-		index string
-		array string
-	)
-	if len(fn.Params) >= 1 {
-		ident = fn.Params[0].Name
-	}
-	if len(fn.Params) >= 2 {
-		index = fn.Params[1].Name
-	}
-	if len(fn.Params) >= 3 {
-		array = fn.Params[2].Name
+	this := Undefined()
+	if len(args) >= 2 {
+		this = args[1]
 	}
-	for i := range a.values {
-		tmp := env.EnclosedEnv[Value](fn.Env)
-		if ident != "" {
-			tmp.Define(ident, a.values[i], false)
-		}
-		if index != "" {
-			tmp.Define(index, CreateFloat(float64(i)), false)
-		}
-		if array != "" {
-			tmp.Define(array, a, false)
-		}
-		v, err := fn.Body.Eval(tmp)
+	for i, n := 0, len(a.values); i < n && i < len(a.values); i++ {
+		v, err := Invoke(fn, this, []Value{a.values[i], CreateFloat(float64(i)), a})
 		if err := apply(v, i, err); err != nil {
 			return err
 		}
@@ -711,6 +669,19 @@ func arrayApplyFunc(a *Array, args []Value, apply func(v Value, i int, err error
 	return nil
 }
 
+func isFunction(v Value) bool {
+	_, ok := v.(Invoker)
+	return ok
+}
+
+func enumerateIndex(n int) []Value {
+	var list []Value
+	for i := 0; i < n; i++ {
+		list = append(list, CreateString(strconv.Itoa(i)))
+	}
+	return list
+}
+
 func normalizeIndex(x, size int) int {
 	if x < 0 {
 		return x + size
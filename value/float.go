@@ -30,16 +30,12 @@ func (f Float) Rev() Value {
 }
 
 func (f Float) Incr() Value {
-	i := int64(f.value)
-	i++
-	f.value = float64(i)
+	f.value++
 	return f
 }
 
 func (f Float) Decr() Value {
-	i := int64(f.value)
-	i--
-	f.value = float64(i)
+	f.value--
 	return f
 }
 
@@ -165,55 +161,38 @@ func (f Float) Mod(other Value) (Value, error) {
 }
 
 func (f Float) Lshift(other Value) (Value, error) {
-	switch x := other.(type) {
-	case Float:
-		tmp := int64(f.value) << int64(x.value)
-		f.value = float64(tmp)
-		return f, nil
-	case undefined:
-		return CreateFloat(math.NaN()), nil
-	default:
-		return nil, ErrIncompatible
-	}
+	f.value = float64(toInt32(f.value) << shiftCount(other))
+	return f, nil
 }
 
 func (f Float) Rshift(other Value) (Value, error) {
-	switch x := other.(type) {
-	case Float:
-		tmp := int64(f.value) >> int64(x.value)
-		f.value = float64(tmp)
-		return f, nil
-	case undefined:
-		return CreateFloat(math.NaN()), nil
-	default:
-		return nil, ErrIncompatible
-	}
+	f.value = float64(toInt32(f.value) >> shiftCount(other))
+	return f, nil
+}
+
+func (f Float) Urshift(other Value) (Value, error) {
+	f.value = float64(toUint32(f.value) >> shiftCount(other))
+	return f, nil
 }
 
 func (f Float) Band(other Value) (Value, error) {
-	switch x := other.(type) {
-	case Float:
-		tmp := int64(f.value) & int64(x.value)
-		f.value = float64(tmp)
-		return f, nil
-	case undefined:
-		return CreateFloat(math.NaN()), nil
-	default:
-		return nil, ErrIncompatible
-	}
+	f.value = float64(toInt32(f.value) & toInt32(ToNumber(other).value))
+	return f, nil
 }
 
 func (f Float) Bor(other Value) (Value, error) {
-	switch x := other.(type) {
-	case Float:
-		tmp := int64(f.value) | int64(x.value)
-		f.value = float64(tmp)
-		return f, nil
-	case undefined:
-		return CreateFloat(math.NaN()), nil
-	default:
-		return nil, ErrIncompatible
-	}
+	f.value = float64(toInt32(f.value) | toInt32(ToNumber(other).value))
+	return f, nil
+}
+
+func (f Float) Bxor(other Value) (Value, error) {
+	f.value = float64(toInt32(f.value) ^ toInt32(ToNumber(other).value))
+	return f, nil
+}
+
+func (f Float) Bnot() Value {
+	f.value = float64(^toInt32(f.value))
+	return f
 }
 
 func (f Float) Compare(other Value) (int, error) {
@@ -285,3 +264,30 @@ func formatNumber(f float64, char byte, args []Value) (Value, error) {
 	str := strconv.FormatFloat(f, char, prec, 64)
 	return CreateString(str), nil
 }
+
+func ToNumber(v Value) Float {
+	n, err := Coerce(v)
+	if err != nil {
+		return Float{value: math.NaN()}
+	}
+	return n.(Float)
+}
+
+func toUint32(f float64) uint32 {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0
+	}
+	f = math.Mod(math.Trunc(f), 1<<32)
+	if f < 0 {
+		f += 1 << 32
+	}
+	return uint32(f)
+}
+
+func toInt32(f float64) int32 {
+	return int32(toUint32(f))
+}
+
+func shiftCount(v Value) uint32 {
+	return toUint32(ToNumber(v).value) & 31
+}
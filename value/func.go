@@ -9,10 +9,62 @@ import (
 )
 
 type Func struct {
-	Ident  string
-	Params []Parameter
-	Body   Evaluable
-	Env    env.Environ[Value]
+	Ident     string
+	Params    []Parameter
+	Body      Evaluable
+	Env       env.Environ[Value]
+	Arrow     bool
+	Generator bool
+	Async     bool
+	Props     *Object
+}
+
+func (f Func) Get(prop string) (Value, error) {
+	switch prop {
+	case "name":
+		return CreateString(f.Ident), nil
+	case "length":
+		var n int
+		for _, p := range f.Params {
+			if p.Optional() {
+				break
+			}
+			n++
+		}
+		return CreateFloat(float64(n)), nil
+	}
+	if f.Props == nil {
+		return Undefined(), nil
+	}
+	return f.Props.Get(prop)
+}
+
+func (f Func) Set(prop string, val Value) error {
+	if f.Props == nil {
+		return ErrOperation
+	}
+	return f.Props.Set(prop, val)
+}
+
+func (f Func) Invoke(this Value, args []Value) (Value, error) {
+	if r, ok := f.Body.(Runnable); ok {
+		return r.Run(f, this, args)
+	}
+	tmp := env.EnclosedEnv[Value](f.Env)
+	if !f.Arrow {
+		tmp.Define("this", this, true)
+	}
+	for i, p := range f.Params {
+		if p.Name == "" {
+			continue
+		}
+		arg := Undefined()
+		if i < len(args) {
+			arg = args[i]
+		}
+		tmp.Define(p.Name, arg, false)
+	}
+	return f.Body.Eval(tmp)
 }
 
 func (_ Func) True() bool {
@@ -38,13 +90,28 @@ func (_ Func) Type() string {
 type Parameter struct {
 	Name  string
 	Value ast.Node
+	Rest  bool
+}
+
+func (p Parameter) Optional() bool {
+	if p.Rest || (p.Name != "" && p.Value != nil) {
+		return true
+	}
+	_, ok := p.Value.(ast.AssignNode)
+	return ok
 }
 
 type BuiltinFunc func(...Value) (Value, error)
 
+// MethodFunc is the signature of builtins that need the value they are called on.
+type MethodFunc func(Value, ...Value) (Value, error)
+
 type Builtin struct {
-	name string
-	call BuiltinFunc
+	name     string
+	call     BuiltinFunc
+	method   MethodFunc
+	props    *Object
+	callOnly bool
 }
 
 func CreateBuiltin(name string, fn BuiltinFunc) Builtin {
@@ -54,6 +121,59 @@ func CreateBuiltin(name string, fn BuiltinFunc) Builtin {
 	}
 }
 
+// CreateFunction creates a builtin that carries its own properties but that can
+// not be used with new.
+func CreateFunction(name string, fn BuiltinFunc) Builtin {
+	b := CreateBuiltin(name, fn)
+	b.props = CreateObject(nil).(*Object)
+	b.callOnly = true
+	return b
+}
+
+// CreateMethod creates a builtin that receives this as its first argument.
+func CreateMethod(name string, fn MethodFunc) Builtin {
+	return Builtin{
+		name:     name,
+		method:   fn,
+		callOnly: true,
+	}
+}
+
+func CreateConstructor(name string, fn BuiltinFunc, proto *Object) Builtin {
+	b := CreateBuiltin(name, fn)
+	b.props = CreateObject(nil).(*Object)
+	b.props.Define("prototype", Descriptor{
+		Value: proto,
+	})
+	proto.Define("constructor", Descriptor{
+		Value:        b,
+		Writable:     true,
+		Configurable: true,
+	})
+	return b
+}
+
+func (b Builtin) Get(prop string) (Value, error) {
+	if prop == "name" {
+		return CreateString(b.name), nil
+	}
+	if b.props == nil {
+		return Undefined(), nil
+	}
+	return b.props.Get(prop)
+}
+
+func (b Builtin) Set(prop string, val Value) error {
+	if b.props == nil {
+		return ErrOperation
+	}
+	return b.props.Set(prop, val)
+}
+
+func (b Builtin) Constructible() bool {
+	return !b.callOnly
+}
+
 func (_ Builtin) True() bool {
 	return true
 }
@@ -66,7 +186,21 @@ func (b Builtin) Type() string {
 	return "builtin"
 }
 
+func (b Builtin) Invoke(this Value, args []Value) (Value, error) {
+	if b.method == nil {
+		return b.Apply(args)
+	}
+	v, err := b.method(this, args...)
+	if err != nil {
+		err = fmt.Errorf("%s: %w", b.name, err)
+	}
+	return v, err
+}
+
 func (b Builtin) Apply(args []Value) (Value, error) {
+	if b.method != nil {
+		return b.Invoke(Undefined(), args)
+	}
 	v, err := b.call(args...)
 	if err != nil {
 		err = fmt.Errorf("%s: %w", b.name, err)
@@ -0,0 +1,109 @@
+package value
+
+import (
+	"fmt"
+)
+
+type Iterator interface {
+	Next() (Value, bool, error)
+}
+
+type Iterable interface {
+	Iterate() Iterator
+}
+
+type iterObject struct {
+	name string
+	Iterator
+}
+
+func CreateIterator(name string, it Iterator) Value {
+	return &iterObject{
+		name:     name,
+		Iterator: it,
+	}
+}
+
+func IterResult(v Value, done bool) Value {
+	obj := CreateObject(nil).(*Object)
+	obj.Set("value", v)
+	obj.Set("done", CreateBool(done))
+	return obj
+}
+
+func (i *iterObject) Iterate() Iterator {
+	return i.Iterator
+}
+
+func (i *iterObject) Spread() ([]Value, error) {
+	var list []Value
+	for {
+		v, done, err := i.Next()
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			break
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+func (i *iterObject) Get(prop string) (Value, error) {
+	if prop != "next" {
+		return Undefined(), nil
+	}
+	next := func(_ ...Value) (Value, error) {
+		v, done, err := i.Next()
+		if err != nil {
+			return nil, err
+		}
+		return IterResult(v, done), nil
+	}
+	return CreateBuiltin(prop, next), nil
+}
+
+func (_ *iterObject) True() bool {
+	return true
+}
+
+func (_ *iterObject) Type() string {
+	return "object"
+}
+
+func (i *iterObject) String() string {
+	return fmt.Sprintf("[object %s]", i.name)
+}
+
+type arrayIterator struct {
+	arr  *Array
+	pos  int
+	read func(int, Value) Value
+}
+
+func (i *arrayIterator) Next() (Value, bool, error) {
+	if i.pos >= i.arr.Len() {
+		return Undefined(), true, nil
+	}
+	v := i.arr.values[i.pos]
+	if i.read != nil {
+		v = i.read(i.pos, v)
+	}
+	i.pos++
+	return v, false, nil
+}
+
+type sliceIterator struct {
+	values []Value
+	pos    int
+}
+
+func (i *sliceIterator) Next() (Value, bool, error) {
+	if i.pos >= len(i.values) {
+		return Undefined(), true, nil
+	}
+	v := i.values[i.pos]
+	i.pos++
+	return v, false, nil
+}
@@ -1,17 +1,34 @@
 package value
 
 import (
+	"fmt"
 	"slices"
 	"strings"
 )
 
 type Descriptor struct {
 	Value
+	Get          Value
+	Set          Value
 	Writable     bool
 	Configurable bool
 	Enumerable   bool
 }
 
+func (d Descriptor) IsAccessor() bool {
+	return d.Get != nil || d.Set != nil
+}
+
+func (d Descriptor) read(this Value) (Value, error) {
+	if !d.IsAccessor() {
+		return d.Value, nil
+	}
+	if d.Get == nil {
+		return Undefined(), nil
+	}
+	return Invoke(d.Get, this, nil)
+}
+
 func createDescriptor(val Value) Descriptor {
 	return Descriptor{
 		Value:        val,
@@ -24,79 +41,303 @@ func createDescriptor(val Value) Descriptor {
 type Object struct {
 	frozen bool
 	sealed bool
-	values map[string]Descriptor
+	proto  *Object
+	keys   []any
+	values map[any]Descriptor
 }
 
 func CreateObject(list map[string]Value) Value {
 	obj := Object{
-		values: make(map[string]Descriptor),
+		values: make(map[any]Descriptor),
+	}
+	keys := make([]string, 0, len(list))
+	for k := range list {
+		keys = append(keys, k)
 	}
-	for k, v := range list {
-		obj.values[k] = createDescriptor(v)
+	slices.Sort(keys)
+	for _, k := range keys {
+		obj.keys = append(obj.keys, k)
+		obj.values[k] = createDescriptor(list[k])
 	}
 	return &obj
 }
 
+func (o *Object) Prototype() *Object {
+	return o.proto
+}
+
+func (o *Object) SetPrototype(proto *Object) {
+	o.proto = proto
+}
+
+func (o *Object) Has(prop string) bool {
+	return o.has(prop)
+}
+
+func (o *Object) HasSymbol(sym *Symbol) bool {
+	return o.has(sym)
+}
+
+func (o *Object) has(key any) bool {
+	if _, ok := o.values[key]; ok {
+		return true
+	}
+	if o.proto != nil {
+		return o.proto.has(key)
+	}
+	return false
+}
+
+func (o *Object) Define(prop string, d Descriptor) error {
+	return o.define(prop, d)
+}
+
+func (o *Object) DefineSymbol(sym *Symbol, d Descriptor) error {
+	return o.define(sym, d)
+}
+
+func (o *Object) define(key any, d Descriptor) error {
+	curr, ok := o.values[key]
+	if !ok {
+		if o.frozen || o.sealed {
+			return ErrOperation
+		}
+		o.keys = append(o.keys, key)
+		o.values[key] = d
+		return nil
+	}
+	if !curr.Configurable {
+		switch {
+		case d.Configurable || d.Enumerable != curr.Enumerable:
+			return ErrOperation
+		case curr.IsAccessor() || d.IsAccessor():
+			return ErrOperation
+		case !curr.Writable && d.Writable:
+			return ErrOperation
+		case !curr.Writable && !sameValue(curr.Value, d.Value):
+			return ErrOperation
+		}
+	}
+	o.values[key] = d
+	return nil
+}
+
+func sameValue(fst, snd Value) bool {
+	if c, ok := fst.(Comparable); ok && fst.Type() == snd.Type() {
+		res, err := c.Compare(snd)
+		return err == nil && res == 0
+	}
+	switch x := fst.(type) {
+	case *Object:
+		y, ok := snd.(*Object)
+		return ok && x == y
+	case *Array:
+		y, ok := snd.(*Array)
+		return ok && x == y
+	default:
+		return IsUndefined(fst) && IsUndefined(snd) || IsNull(fst) && IsNull(snd)
+	}
+}
+
 func (o *Object) Keys() Value {
+	return CreateArray(o.Enumerate())
+}
+
+func (o *Object) Enumerate() []Value {
 	var list []Value
-	for k, v := range o.values {
-		if !v.Enumerable {
+	for _, k := range o.Names() {
+		if v := o.values[k]; !v.Enumerable {
 			continue
 		}
 		list = append(list, CreateString(k))
 	}
-	slices.SortFunc(list, func(s1, s2 Value) int {
-		return strings.Compare(s1.String(), s2.String())
-	})
-	return CreateArray(list)
+	return list
 }
 
 func (o *Object) Freeze() {
 	o.frozen = true
+	for k, d := range o.values {
+		d.Configurable = false
+		if !d.IsAccessor() {
+			d.Writable = false
+		}
+		o.values[k] = d
+	}
 }
 
 func (o *Object) Seal() {
 	o.sealed = true
+	for k, d := range o.values {
+		d.Configurable = false
+		o.values[k] = d
+	}
 }
 
 func (o *Object) At(ix Value) (Value, error) {
-	v, ok := o.values[ix.String()]
-	if !ok {
-		return Undefined(), nil
+	if sym, ok := ix.(*Symbol); ok {
+		return o.GetSymbol(sym)
+	}
+	return o.Get(ix.String())
+}
+
+func (o *Object) SetAt(ix Value, val Value) error {
+	if sym, ok := ix.(*Symbol); ok {
+		return o.SetSymbol(sym, val)
 	}
-	return v, nil
+	return o.Set(ix.String(), val)
 }
 
 func (o *Object) Get(prop string) (Value, error) {
-	if prop == "length" {
+	v, err := o.get(prop, o)
+	if err != nil || !IsUndefined(v) || o.has(prop) {
+		return v, err
+	}
+	switch prop {
+	case "length":
 		return CreateFloat(float64(len(o.values))), nil
+	case "toString":
+		str := func(_ ...Value) (Value, error) {
+			return CreateString(ObjectTag(o)), nil
+		}
+		return CreateBuiltin(prop, str), nil
+	default:
+		return v, nil
 	}
-	v, ok := o.values[prop]
-	if !ok {
-		return Undefined(), nil
+}
+
+// GetFrom looks prop up from o but runs a getter with this as receiver.
+func (o *Object) GetFrom(prop string, this Value) (Value, error) {
+	return o.get(prop, this)
+}
+
+func (o *Object) GetSymbol(sym *Symbol) (Value, error) {
+	return o.get(sym, o)
+}
+
+func (o *Object) get(key any, this Value) (Value, error) {
+	if d, ok := o.values[key]; ok {
+		return d.read(this)
+	}
+	if o.proto != nil {
+		return o.proto.get(key, this)
+	}
+	return Undefined(), nil
+}
+
+func (o *Object) lookup(key any) (Descriptor, bool) {
+	if d, ok := o.values[key]; ok {
+		return d, ok
+	}
+	if o.proto != nil {
+		return o.proto.lookup(key)
+	}
+	return Descriptor{}, false
+}
+
+func (o *Object) GetOwn(prop string) (Descriptor, bool) {
+	d, ok := o.values[prop]
+	return d, ok
+}
+
+func (o *Object) GetOwnSymbol(sym *Symbol) (Descriptor, bool) {
+	d, ok := o.values[sym]
+	return d, ok
+}
+
+func (o *Object) Names() []string {
+	var list []string
+	for _, k := range o.keys {
+		if k, ok := k.(string); ok {
+			list = append(list, k)
+		}
+	}
+	return list
+}
+
+func (o *Object) Symbols() []*Symbol {
+	var list []*Symbol
+	for _, k := range o.keys {
+		if k, ok := k.(*Symbol); ok {
+			list = append(list, k)
+		}
 	}
-	return v, nil
+	return list
 }
 
 func (o *Object) Set(prop string, val Value) error {
-	if o.frozen {
+	return o.set(prop, val)
+}
+
+// SetFrom assigns prop on this, calling the setter found from o if any.
+func (o *Object) SetFrom(prop string, val, this Value) error {
+	if d, ok := o.lookup(prop); ok && d.IsAccessor() {
+		if d.Set == nil {
+			return ErrOperation
+		}
+		_, err := Invoke(d.Set, this, []Value{val})
+		return err
+	}
+	return Set(this, prop, val)
+}
+
+func (o *Object) SetSymbol(sym *Symbol, val Value) error {
+	return o.set(sym, val)
+}
+
+func (o *Object) set(key any, val Value) error {
+	d, ok := o.lookup(key)
+	if ok && d.IsAccessor() {
+		if d.Set == nil {
+			return ErrOperation
+		}
+		_, err := Invoke(d.Set, o, []Value{val})
+		return err
+	}
+	if ok && !d.Writable {
 		return ErrOperation
 	}
-	d := o.values[prop]
-	if !d.Writable {
+	if d, ok := o.values[key]; ok {
+		d.Value = val
+		o.values[key] = d
+		return nil
+	}
+	if o.frozen || o.sealed {
 		return ErrOperation
 	}
-	d.Value = val
-	o.values[prop] = d
+	o.keys = append(o.keys, key)
+	o.values[key] = createDescriptor(val)
 	return nil
 }
 
+// ObjectTag gives the string Object.prototype.toString would produce for v,
+// honoring a Symbol.toStringTag property when it resolves to a string.
+func ObjectTag(v Value) string {
+	tag := "Object"
+	switch v := v.(type) {
+	case *Object:
+		if t, err := v.GetSymbol(SymbolToStringTag); err == nil {
+			if t, ok := t.(Str); ok {
+				tag = t.value
+			}
+		}
+	case *Array:
+		tag = "Array"
+	case Func, Builtin:
+		tag = "Function"
+	case nil, undefined:
+		tag = "Undefined"
+	case null:
+		tag = "Null"
+	}
+	return fmt.Sprintf("[object %s]", tag)
+}
+
 func (o *Object) Call(fn string, args []Value) (Value, error) {
 	return nil, nil
 }
 
 func (o *Object) True() bool {
-	return len(o.values) > 0
+	return true
 }
 
 func (o *Object) String() string {
@@ -104,7 +345,11 @@ func (o *Object) String() string {
 	str.WriteRune('{')
 
 	var i int
-	for k, v := range o.values {
+	for _, k := range o.Names() {
+		v := o.values[k]
+		if !v.Enumerable {
+			continue
+		}
 		if i > 0 {
 			str.WriteRune(',')
 			str.WriteRune(' ')
@@ -112,7 +357,11 @@ func (o *Object) String() string {
 		i++
 		str.WriteString(k)
 		str.WriteRune(':')
-		str.WriteString(v.String())
+		if v.IsAccessor() {
+			str.WriteString(accessorString(v))
+		} else {
+			str.WriteString(v.String())
+		}
 	}
 	str.WriteRune('}')
 	return str.String()
@@ -121,3 +370,14 @@ func (o *Object) String() string {
 func (o *Object) Type() string {
 	return "object"
 }
+
+func accessorString(d Descriptor) string {
+	switch {
+	case d.Get != nil && d.Set != nil:
+		return "[Getter/Setter]"
+	case d.Get != nil:
+		return "[Getter]"
+	default:
+		return "[Setter]"
+	}
+}
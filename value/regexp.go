@@ -0,0 +1,452 @@
+package value
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrPattern = errors.New("invalid regular expression")
+
+type RegExp struct {
+	source    string
+	flags     string
+	expr      *regexp.Regexp
+	offset    *regexp.Regexp
+	lastIndex int
+}
+
+func CreateRegExp(pattern, flags string) (Value, error) {
+	expr, err := compileRegExp(pattern, flags)
+	if err != nil {
+		return nil, err
+	}
+	re := RegExp{
+		source: pattern,
+		flags:  flags,
+		expr:   expr,
+	}
+	return &re, nil
+}
+
+func (r *RegExp) Get(prop string) (Value, error) {
+	switch prop {
+	case "source":
+		return CreateString(r.source), nil
+	case "flags":
+		return CreateString(r.flags), nil
+	case "lastIndex":
+		return CreateFloat(float64(r.lastIndex)), nil
+	case "global":
+		return CreateBool(r.has('g')), nil
+	case "ignoreCase":
+		return CreateBool(r.has('i')), nil
+	case "multiline":
+		return CreateBool(r.has('m')), nil
+	case "dotAll":
+		return CreateBool(r.has('s')), nil
+	case "sticky":
+		return CreateBool(r.has('y')), nil
+	case "unicode":
+		return CreateBool(r.has('u')), nil
+	case "hasIndices":
+		return CreateBool(r.has('d')), nil
+	default:
+		return Undefined(), nil
+	}
+}
+
+func (r *RegExp) Set(prop string, val Value) error {
+	if prop != "lastIndex" {
+		return ErrOperation
+	}
+	ix, err := toNativeInt(val)
+	if err != nil {
+		return err
+	}
+	r.lastIndex = ix
+	return nil
+}
+
+func (r *RegExp) Call(fn string, args []Value) (Value, error) {
+	call, ok := regexpPrototype[fn]
+	if !ok {
+		return nil, fmt.Errorf("%s not defined on regexp", fn)
+	}
+	return call(r, args)
+}
+
+func (_ *RegExp) True() bool {
+	return true
+}
+
+func (_ *RegExp) Type() string {
+	return "object"
+}
+
+func (r *RegExp) String() string {
+	return fmt.Sprintf("/%s/%s", r.source, r.flags)
+}
+
+func (r *RegExp) Exec(str string) Value {
+	var (
+		start = 0
+		keep  = r.has('g') || r.has('y')
+	)
+	if keep {
+		start = byteOffset(str, r.lastIndex)
+	}
+	if start < 0 {
+		r.lastIndex = 0
+		return Null()
+	}
+	loc := r.find(str, start)
+	if loc == nil {
+		if keep {
+			r.lastIndex = 0
+		}
+		return Null()
+	}
+	if keep {
+		r.lastIndex = utf16Offset(str, loc[1])
+	}
+	return r.match(str, loc)
+}
+
+// find returns the leftmost match starting at or after the byte offset start.
+// The text before start stays visible so that ^ and \b see the right context.
+func (r *RegExp) find(str string, start int) []int {
+	if start == 0 {
+		loc := r.expr.FindStringSubmatchIndex(str)
+		if loc == nil || (r.has('y') && loc[0] != 0) {
+			return nil
+		}
+		return loc
+	}
+	_, size := utf8.DecodeLastRuneInString(str[:start])
+	prev := start - size
+	loc := r.offsetExpr().FindStringSubmatchIndex(str[prev:])
+	if loc == nil {
+		return nil
+	}
+	loc = loc[2:]
+	for i := range loc {
+		if loc[i] >= 0 {
+			loc[i] += prev
+		}
+	}
+	return loc
+}
+
+// offsetExpr wraps the expression so that it skips the rune preceding the
+// search offset and, unless the regexp is sticky, any text after it.
+func (r *RegExp) offsetExpr() *regexp.Regexp {
+	if r.offset == nil {
+		skip := `(?s:.*?)`
+		if r.has('y') {
+			skip = ""
+		}
+		r.offset = regexp.MustCompile(fmt.Sprintf(`\A(?s:.)%s(%s)`, skip, r.expr))
+	}
+	return r.offset
+}
+
+func (r *RegExp) has(flag rune) bool {
+	return strings.ContainsRune(r.flags, flag)
+}
+
+func (r *RegExp) matches(str string) [][]int {
+	if !r.has('g') {
+		loc := r.expr.FindStringSubmatchIndex(str)
+		if loc == nil {
+			return nil
+		}
+		return [][]int{loc}
+	}
+	return r.expr.FindAllStringSubmatchIndex(str, -1)
+}
+
+func (r *RegExp) match(str string, loc []int) Value {
+	return matchArray(str, loc, r.expr.SubexpNames())
+}
+
+func matchArray(str string, loc []int, names []string) *Array {
+	var (
+		list   []Value
+		groups = CreateObject(nil).(*Object)
+		named  bool
+	)
+	for i := 0; i < len(loc); i += 2 {
+		v := Undefined()
+		if loc[i] >= 0 {
+			v = CreateString(str[loc[i]:loc[i+1]])
+		}
+		list = append(list, v)
+		if n := names[i/2]; n != "" {
+			groups.Set(n, v)
+			named = true
+		}
+	}
+	arr := CreateArray(list).(*Array)
+	arr.props = CreateObject(nil).(*Object)
+	arr.props.Set("index", CreateFloat(float64(utf16Offset(str, loc[0]))))
+	arr.props.Set("input", CreateString(str))
+	if named {
+		arr.props.Set("groups", groups)
+	} else {
+		arr.props.Set("groups", Undefined())
+	}
+	return arr
+}
+
+func replaceMatches(str string, locs [][]int, names []string, rep Value) (Value, error) {
+	var (
+		buf  strings.Builder
+		last int
+	)
+	for _, loc := range locs {
+		buf.WriteString(str[last:loc[0]])
+		r, err := expandMatch(str, loc, names, rep)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(r)
+		last = loc[1]
+	}
+	buf.WriteString(str[last:])
+	return CreateString(buf.String()), nil
+}
+
+func expandMatch(str string, loc []int, names []string, rep Value) (string, error) {
+	if _, ok := rep.(Invoker); !ok {
+		return expandReplacement(str, loc, names, rep.String()), nil
+	}
+	m := matchArray(str, loc, names)
+	args := append(m.values, CreateFloat(float64(utf16Offset(str, loc[0]))), CreateString(str))
+	if g, _ := m.props.Get("groups"); !IsUndefined(g) {
+		args = append(args, g)
+	}
+	res, err := Invoke(rep, Undefined(), args)
+	if err != nil {
+		return "", err
+	}
+	return res.String(), nil
+}
+
+func expandReplacement(str string, loc []int, names []string, rep string) string {
+	var (
+		buf   strings.Builder
+		group = func(i int) string {
+			if i*2 >= len(loc) || loc[i*2] < 0 {
+				return ""
+			}
+			return str[loc[i*2]:loc[i*2+1]]
+		}
+	)
+	for i := 0; i < len(rep); i++ {
+		if rep[i] != '$' || i+1 >= len(rep) {
+			buf.WriteByte(rep[i])
+			continue
+		}
+		switch c := rep[i+1]; {
+		case c == '$':
+			buf.WriteByte('$')
+			i++
+		case c == '&':
+			buf.WriteString(str[loc[0]:loc[1]])
+			i++
+		case c == '`':
+			buf.WriteString(str[:loc[0]])
+			i++
+		case c == '\'':
+			buf.WriteString(str[loc[1]:])
+			i++
+		case c >= '0' && c <= '9':
+			n, size := int(c-'0'), 1
+			if i+2 < len(rep) && rep[i+2] >= '0' && rep[i+2] <= '9' {
+				if m := n*10 + int(rep[i+2]-'0'); m*2 < len(loc) {
+					n, size = m, 2
+				}
+			}
+			if n == 0 || n*2 >= len(loc) {
+				buf.WriteByte('$')
+				continue
+			}
+			buf.WriteString(group(n))
+			i += size
+		case c == '<':
+			end := strings.IndexByte(rep[i:], '>')
+			if end < 0 {
+				buf.WriteByte('$')
+				continue
+			}
+			name := rep[i+2 : i+end]
+			for j, n := range names {
+				if n == name && n != "" {
+					buf.WriteString(group(j))
+				}
+			}
+			i += end
+		default:
+			buf.WriteByte('$')
+		}
+	}
+	return buf.String()
+}
+
+var regexpPrototype = map[string]ValueFunc[*RegExp]{
+	"exec":     CheckArity(1, regexpExec),
+	"test":     CheckArity(1, regexpTest),
+	"toString": CheckArity(0, regexpToString),
+}
+
+func regexpExec(r *RegExp, args []Value) (Value, error) {
+	return r.Exec(args[0].String()), nil
+}
+
+func regexpTest(r *RegExp, args []Value) (Value, error) {
+	res := r.Exec(args[0].String())
+	return CreateBool(!IsNull(res)), nil
+}
+
+func regexpToString(r *RegExp, _ []Value) (Value, error) {
+	return CreateString(r.String()), nil
+}
+
+// compileRegExp translates a JavaScript pattern into the RE2 syntax used by
+// the regexp package. RE2 guarantees linear time matching and therefore has
+// no support for backreferences (\1, \k<name>) nor for lookaround assertions
+// ((?=...), (?!...), (?<=...), (?<!...)): patterns using them are rejected
+// with ErrPattern instead of being silently misinterpreted.
+func compileRegExp(pattern, flags string) (*regexp.Regexp, error) {
+	var prefix string
+	for i, f := range flags {
+		if !strings.ContainsRune("dgimsuy", f) || strings.ContainsRune(flags[i+1:], f) {
+			return nil, fmt.Errorf("invalid flags %q: %w", flags, ErrPattern)
+		}
+		switch f {
+		case 'i', 'm', 's':
+			prefix += string(f)
+		}
+	}
+	expr, err := translateRegExp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if prefix != "" {
+		expr = fmt.Sprintf("(?%s)%s", prefix, expr)
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("/%s/: %s: %w", pattern, err, ErrPattern)
+	}
+	return re, nil
+}
+
+func translateRegExp(pattern string) (string, error) {
+	var (
+		buf   strings.Builder
+		class bool
+	)
+	unsupported := func(what string) error {
+		return fmt.Errorf("/%s/: %s not supported: %w", pattern, what, ErrPattern)
+	}
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			switch n := pattern[i]; {
+			case n >= '1' && n <= '9' && !class:
+				return "", unsupported("backreference")
+			case n == 'k' && !class:
+				return "", unsupported("named backreference")
+			case n == '0':
+				buf.WriteString(`\x00`)
+			case n == '/':
+				buf.WriteByte('/')
+			case n == 'u' && i+1 < len(pattern) && pattern[i+1] == '{':
+				end := strings.IndexByte(pattern[i:], '}')
+				if end < 0 {
+					return "", unsupported("unterminated unicode escape")
+				}
+				buf.WriteString(`\x`)
+				buf.WriteString(pattern[i+1 : i+end+1])
+				i += end
+			case n == 'u' && i+4 < len(pattern):
+				buf.WriteString(`\x{`)
+				buf.WriteString(pattern[i+1 : i+5])
+				buf.WriteByte('}')
+				i += 4
+			case n == 'c' && i+1 < len(pattern):
+				fmt.Fprintf(&buf, `\x{%x}`, pattern[i+1]%32)
+				i++
+			default:
+				buf.WriteByte('\\')
+				buf.WriteByte(n)
+			}
+		case c == '[' && !class:
+			class = true
+			if strings.HasPrefix(pattern[i:], "[^]") {
+				buf.WriteString(`[\x00-\x{10FFFF}]`)
+				class = false
+				i += 2
+				continue
+			}
+			buf.WriteByte(c)
+		case c == ']' && class:
+			class = false
+			buf.WriteByte(c)
+		case c == '(' && !class && strings.HasPrefix(pattern[i:], "(?"):
+			rest := pattern[i+2:]
+			switch {
+			case strings.HasPrefix(rest, "="), strings.HasPrefix(rest, "!"):
+				return "", unsupported("lookahead")
+			case strings.HasPrefix(rest, "<="), strings.HasPrefix(rest, "<!"):
+				return "", unsupported("lookbehind")
+			case strings.HasPrefix(rest, "<"):
+				buf.WriteString("(?P<")
+				i += 2
+			default:
+				buf.WriteByte(c)
+			}
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return buf.String(), nil
+}
+
+// utf16Offset converts a byte offset in str into a position counted in UTF-16
+// code units, as JavaScript reports string indices.
+func utf16Offset(str string, offset int) int {
+	var n int
+	for _, c := range str[:offset] {
+		n++
+		if c > 0xFFFF {
+			n++
+		}
+	}
+	return n
+}
+
+// byteOffset is the inverse of utf16Offset. It returns -1 if pos is past the
+// end of str.
+func byteOffset(str string, pos int) int {
+	var n int
+	for i, c := range str {
+		if n >= pos {
+			return i
+		}
+		n++
+		if c > 0xFFFF {
+			n++
+		}
+	}
+	if n >= pos && pos >= 0 {
+		return len(str)
+	}
+	return -1
+}
@@ -2,6 +2,7 @@ package value
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 	"unicode"
 )
@@ -20,7 +21,11 @@ func (s Str) True() bool {
 	return s.value != ""
 }
 
-func (s Str) Spread() []Value {
+func (s Str) Spread() ([]Value, error) {
+	return s.chars(), nil
+}
+
+func (s Str) chars() []Value {
 	var list []Value
 	for _, c := range strings.Split(s.value, "") {
 		list = append(list, CreateString(c))
@@ -28,6 +33,16 @@ func (s Str) Spread() []Value {
 	return list
 }
 
+func (s Str) Iterate() Iterator {
+	return &sliceIterator{
+		values: s.chars(),
+	}
+}
+
+func (s Str) Enumerate() []Value {
+	return enumerateIndex(s.Len())
+}
+
 func (s Str) Len() int {
 	return len(s.value)
 }
@@ -100,11 +115,14 @@ var stringPrototype = map[string]ValueFunc[Str]{
 	"endsWith":    CheckArity(1, strEndsWith),
 	"includes":    CheckArity(1, strIncludes),
 	"indexOf":     CheckArity(1, strIndexOf),
+	"match":       CheckArity(0, strMatch),
+	"matchAll":    CheckArity(1, strMatchAll),
 	"padEnd":      CheckArity(1, strPadEnd),
 	"padStart":    CheckArity(1, strPadStart),
 	"repeat":      CheckArity(1, strRepeat),
 	"replace":     CheckArity(2, strReplace),
 	"replaceAll":  CheckArity(2, strReplaceAll),
+	"search":      CheckArity(1, strSearch),
 	"slice":       CheckArity(1, strSlice),
 	"split":       CheckArity(0, strSplit),
 	"startsWith":  CheckArity(1, strStartsWith),
@@ -238,11 +256,21 @@ func strRepeat(s Str, args []Value) (Value, error) {
 }
 
 func strReplace(s Str, args []Value) (Value, error) {
-	pat := args[0].String()
-	rep := args[1].String()
-
-	s.value = strings.Replace(s.value, pat, rep, 1)
-	return s, nil
+	if re, ok := args[0].(*RegExp); ok {
+		locs := re.matches(s.value)
+		if re.has('g') {
+			re.lastIndex = 0
+		}
+		return replaceMatches(s.value, locs, re.expr.SubexpNames(), args[1])
+	}
+	var (
+		pat  = args[0].String()
+		locs [][]int
+	)
+	if ix := strings.Index(s.value, pat); ix >= 0 {
+		locs = append(locs, []int{ix, ix + len(pat)})
+	}
+	return replaceMatches(s.value, locs, []string{""}, args[1])
 }
 
 func strSlice(s Str, args []Value) (Value, error) {
@@ -283,36 +311,55 @@ func strSlice(s Str, args []Value) (Value, error) {
 
 func strSplit(s Str, args []Value) (Value, error) {
 	var (
-		limit int
+		limit = -1
 		err   error
-		sep   string
 	)
 	switch len(args) {
 	case 0:
 		return CreateArray([]Value{s}), nil
 	case 1:
-		limit--
-		sep = args[0].String()
 	case 2:
-		sep = args[0].String()
 		limit, err = toNativeInt(args[1])
 		if err != nil {
 			return nil, err
 		}
-		if limit == 0 {
-			return CreateArray(nil), nil
-		}
 	default:
 		return nil, ErrArgument
 	}
+	var list []Value
+	if re, ok := args[0].(*RegExp); ok {
+		list = splitRegExp(s.value, re)
+	} else {
+		for _, p := range strings.Split(s.value, args[0].String()) {
+			list = append(list, CreateString(p))
+		}
+	}
+	if limit >= 0 && limit < len(list) {
+		list = list[:limit]
+	}
+	return CreateArray(list), nil
+}
+
+func splitRegExp(str string, re *RegExp) []Value {
 	var (
-		parts = strings.Split(s.value, sep)
-		list  []Value
+		list []Value
+		last int
 	)
-	for i := 0; i < limit && i < len(parts); i++ {
-		list = append(list, CreateString(parts[i]))
+	for _, loc := range re.expr.FindAllStringSubmatchIndex(str, -1) {
+		if loc[0] == loc[1] && (loc[0] == 0 || loc[0] == len(str)) {
+			continue
+		}
+		list = append(list, CreateString(str[last:loc[0]]))
+		for i := 2; i < len(loc); i += 2 {
+			if loc[i] < 0 {
+				list = append(list, Undefined())
+			} else {
+				list = append(list, CreateString(str[loc[i]:loc[i+1]]))
+			}
+		}
+		last = loc[1]
 	}
-	return CreateArray(list), nil
+	return append(list, CreateString(str[last:]))
 }
 
 func strStartsWith(s Str, args []Value) (Value, error) {
@@ -371,16 +418,95 @@ func strTrimRight(s Str, _ []Value) (Value, error) {
 }
 
 func strReplaceAll(s Str, args []Value) (Value, error) {
-	s1, ok := args[0].(Str)
-	if !ok {
-		return nil, ErrIncompatible
+	if re, ok := args[0].(*RegExp); ok {
+		if !re.has('g') {
+			return nil, fmt.Errorf("replaceAll must be called with a global RegExp: %w", ErrOperation)
+		}
+		return strReplace(s, args)
 	}
-	s2, ok := args[1].(Str)
+	pat, ok := args[0].(Str)
 	if !ok {
 		return nil, ErrIncompatible
 	}
-	s.value = strings.ReplaceAll(s.value, s1.value, s2.value)
-	return s, nil
+	var locs [][]int
+	for i := 0; i <= len(s.value); {
+		ix := strings.Index(s.value[i:], pat.value)
+		if ix < 0 {
+			break
+		}
+		locs = append(locs, []int{i + ix, i + ix + len(pat.value)})
+		i += ix + max(len(pat.value), 1)
+	}
+	return replaceMatches(s.value, locs, []string{""}, args[1])
+}
+
+func strMatch(s Str, args []Value) (Value, error) {
+	re, err := toRegExp(args, "")
+	if err != nil {
+		return nil, err
+	}
+	if !re.has('g') {
+		return re.Exec(s.value), nil
+	}
+	re.lastIndex = 0
+	var list []Value
+	for _, loc := range re.matches(s.value) {
+		list = append(list, CreateString(s.value[loc[0]:loc[1]]))
+	}
+	if len(list) == 0 {
+		return Null(), nil
+	}
+	return CreateArray(list), nil
+}
+
+func strMatchAll(s Str, args []Value) (Value, error) {
+	re, err := toRegExp(args, "g")
+	if err != nil {
+		return nil, err
+	}
+	if !re.has('g') {
+		return nil, fmt.Errorf("matchAll must be called with a global RegExp: %w", ErrOperation)
+	}
+	var (
+		names = re.expr.SubexpNames()
+		list  []Value
+	)
+	for _, loc := range re.matches(s.value) {
+		list = append(list, matchArray(s.value, loc, names))
+	}
+	it := sliceIterator{
+		values: list,
+	}
+	return CreateIterator("RegExp String Iterator", &it), nil
+}
+
+func strSearch(s Str, args []Value) (Value, error) {
+	re, err := toRegExp(args, "")
+	if err != nil {
+		return nil, err
+	}
+	loc := re.expr.FindStringIndex(s.value)
+	if loc == nil {
+		return CreateFloat(-1), nil
+	}
+	return CreateFloat(float64(utf16Offset(s.value, loc[0]))), nil
+}
+
+func toRegExp(args []Value, flags string) (*RegExp, error) {
+	if len(args) > 0 {
+		if re, ok := args[0].(*RegExp); ok {
+			return re, nil
+		}
+	}
+	var pattern string
+	if len(args) > 0 && !IsUndefined(args[0]) {
+		pattern = regexp.QuoteMeta(args[0].String())
+	}
+	re, err := CreateRegExp(pattern, flags)
+	if err != nil {
+		return nil, err
+	}
+	return re.(*RegExp), nil
 }
 
 func strUpper(s Str, _ []Value) (Value, error) {
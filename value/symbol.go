@@ -0,0 +1,110 @@
+package value
+
+import (
+	"fmt"
+	"sync"
+)
+
+type Symbol struct {
+	desc  Value
+	entry bool
+}
+
+var (
+	SymbolIterator      = createWellKnown("Symbol.iterator")
+	SymbolAsyncIterator = createWellKnown("Symbol.asyncIterator")
+	SymbolToPrimitive   = createWellKnown("Symbol.toPrimitive")
+	SymbolToStringTag   = createWellKnown("Symbol.toStringTag")
+)
+
+var symbolRegistry = struct {
+	sync.Mutex
+	symbols map[string]*Symbol
+}{
+	symbols: make(map[string]*Symbol),
+}
+
+func CreateSymbol(desc Value) *Symbol {
+	if desc == nil {
+		desc = Undefined()
+	}
+	return &Symbol{
+		desc: desc,
+	}
+}
+
+func createWellKnown(desc string) *Symbol {
+	return CreateSymbol(CreateString(desc))
+}
+
+func SymbolFor(key string) *Symbol {
+	symbolRegistry.Lock()
+	defer symbolRegistry.Unlock()
+	if s, ok := symbolRegistry.symbols[key]; ok {
+		return s
+	}
+	s := CreateSymbol(CreateString(key))
+	s.entry = true
+	symbolRegistry.symbols[key] = s
+	return s
+}
+
+func SymbolKeyFor(s *Symbol) Value {
+	if !s.entry {
+		return Undefined()
+	}
+	return s.desc
+}
+
+func (s *Symbol) Description() Value {
+	return s.desc
+}
+
+func (s *Symbol) Get(prop string) (Value, error) {
+	if prop != "description" {
+		return Undefined(), nil
+	}
+	return s.desc, nil
+}
+
+func (s *Symbol) Call(fn string, args []Value) (Value, error) {
+	call, ok := symbolPrototype[fn]
+	if !ok {
+		return nil, fmt.Errorf("%s not defined on symbol", fn)
+	}
+	return call(s, args)
+}
+
+func (s *Symbol) Compare(other Value) (int, error) {
+	x, ok := other.(*Symbol)
+	if !ok {
+		return 0, ErrIncompatible
+	}
+	if s != x {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (_ *Symbol) True() bool {
+	return true
+}
+
+func (_ *Symbol) Type() string {
+	return "symbol"
+}
+
+func (s *Symbol) String() string {
+	if IsUndefined(s.desc) {
+		return "Symbol()"
+	}
+	return fmt.Sprintf("Symbol(%s)", s.desc)
+}
+
+var symbolPrototype = map[string]ValueFunc[*Symbol]{
+	"toString": CheckArity(0, symbolToString),
+}
+
+func symbolToString(s *Symbol, _ []Value) (Value, error) {
+	return CreateString(s.String()), nil
+}
@@ -29,7 +29,7 @@ type Evaluable interface {
 }
 
 type Spreadable interface {
-	Spread() []Value
+	Spread() ([]Value, error)
 }
 
 type Enumerable interface {
@@ -69,6 +69,22 @@ func compare(fst, snd Value) (int, error) {
 	return cmp.Compare(snd)
 }
 
+type Runnable interface {
+	Run(Func, Value, []Value) (Value, error)
+}
+
+type Invoker interface {
+	Invoke(Value, []Value) (Value, error)
+}
+
+func Invoke(fn, this Value, args []Value) (Value, error) {
+	i, ok := fn.(Invoker)
+	if !ok {
+		return nil, ErrOperation
+	}
+	return i.Invoke(this, args)
+}
+
 type Callable interface {
 	Call(string, []Value) (Value, error)
 }
@@ -85,6 +101,18 @@ func At(v, ix Value) (Value, error) {
 	return at.At(ix)
 }
 
+type IndexSetter interface {
+	SetAt(Value, Value) error
+}
+
+func SetAt(v, ix, val Value) error {
+	at, ok := v.(IndexSetter)
+	if !ok {
+		return ErrOperation
+	}
+	return at.SetAt(ix, val)
+}
+
 type Getter interface {
 	Get(string) (Value, error)
 }
@@ -154,19 +182,19 @@ func Reverse(v Value) (Value, error) {
 }
 
 func Increment(v Value) (Value, error) {
-	f, ok := v.(Float)
-	if !ok {
-		return nil, ErrOperation
+	v, err := Coerce(v)
+	if err != nil {
+		return nil, err
 	}
-	return f.Incr(), nil
+	return v.(Float).Incr(), nil
 }
 
 func Decrement(v Value) (Value, error) {
-	f, ok := v.(Float)
-	if !ok {
-		return nil, ErrOperation
+	v, err := Coerce(v)
+	if err != nil {
+		return nil, err
 	}
-	return f.Decr(), nil
+	return v.(Float).Decr(), nil
 }
 
 type Spread struct {
@@ -183,15 +211,11 @@ func SpreadValue(v Value) (Value, error) {
 	return s, nil
 }
 
-func (s Spread) Spread() []Value {
-	switch s := s.Value.(type) {
-	case *Array:
+func (s Spread) Spread() ([]Value, error) {
+	if s, ok := s.Value.(Spreadable); ok {
 		return s.Spread()
-	case Str:
-		return s.Spread()
-	default:
-		return nil
 	}
+	return nil, nil
 }
 
 func toNativeInt(v Value) (int, error) {